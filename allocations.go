@@ -0,0 +1,153 @@
+package fossil
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+//***** Structures *****//
+
+// NodeAllocation is an IP and port pair of a node that can be assigned to a server
+type NodeAllocation struct {
+	ID       int    `json:"id"`
+	IP       string `json:"ip"`
+	Alias    string `json:"alias"`
+	Port     int    `json:"port"`
+	Notes    string `json:"notes"`
+	Assigned bool   `json:"assigned"`
+}
+
+// ErrNoFreeAllocation is returned by FindFreeAllocation when every allocation of the node is assigned
+var ErrNoFreeAllocation = errors.New("no unassigned allocations left on the node")
+
+//***** Helpers *****//
+
+// PortRange formats a range of ports, both ends included, as expected by CreateAllocations
+func PortRange(from, to int) string {
+	return fmt.Sprintf("%d-%d", from, to)
+}
+
+//***** String *****//
+
+func (a *NodeAllocation) String() string {
+	return a.IP + ":" + strconv.Itoa(a.Port)
+}
+
+//***** Pagination *****//
+
+// NodeAllocationIterator lazily walks the allocations of a node, fetching the pages as needed
+type NodeAllocationIterator struct {
+	pager
+	allocation *NodeAllocation
+}
+
+// Next advances to the next allocation, returning false when there are no more or an error occurred
+func (it *NodeAllocationIterator) Next() bool {
+	var v NodeAllocation
+	if !it.next(&v) {
+		return false
+	}
+
+	it.allocation = &v
+	return true
+}
+
+// Value returns the current allocation
+func (it *NodeAllocationIterator) Value() *NodeAllocation {
+	return it.allocation
+}
+
+//***** Requests *****//
+
+// GetAllocations fetches all the allocations of a node. The results can be optionally filtered and sorted with
+// ListOptions.
+func (c *ApplicationCredentials) GetAllocations(nodeID int, opts ...*ListOptions) ([]*NodeAllocation, error) {
+	return c.GetAllocationsContext(context.Background(), nodeID, opts...)
+}
+
+// GetAllocationsContext is GetAllocations with a context
+func (c *ApplicationCredentials) GetAllocationsContext(ctx context.Context, nodeID int,
+	opts ...*ListOptions) (allocs []*NodeAllocation, err error) {
+	it := c.AllocationsIterContext(ctx, nodeID, listOptions(opts))
+	for it.Next() {
+		allocs = append(allocs, it.Value())
+	}
+
+	return allocs, it.Err()
+}
+
+// AllocationsIter returns an iterator over the allocations of a node. The pages are fetched as they are needed.
+func (c *ApplicationCredentials) AllocationsIter(nodeID int, opts *ListOptions) *NodeAllocationIterator {
+	return c.AllocationsIterContext(context.Background(), nodeID, opts)
+}
+
+// AllocationsIterContext is AllocationsIter with a context
+func (c *ApplicationCredentials) AllocationsIterContext(ctx context.Context, nodeID int,
+	opts *ListOptions) *NodeAllocationIterator {
+	target := c.endpointURL(fmt.Sprintf("nodes/%d/allocations", nodeID))
+	return &NodeAllocationIterator{pager: newPager(ctx, (*Credentials)(c), target, opts)}
+}
+
+// CreateAllocations adds allocations to a node for the given IP. Each port can be a single port, like "25565",
+// or a range, like "25565-25600" (see PortRange). The alias is optional.
+func (c *ApplicationCredentials) CreateAllocations(nodeID int, ip, alias string, ports ...string) error {
+	return c.CreateAllocationsContext(context.Background(), nodeID, ip, alias, ports...)
+}
+
+// CreateAllocationsContext is CreateAllocations with a context
+func (c *ApplicationCredentials) CreateAllocationsContext(ctx context.Context, nodeID int, ip, alias string,
+	ports ...string) (err error) {
+	type wrapper struct {
+		IP    string   `json:"ip"`
+		Alias string   `json:"alias,omitempty"`
+		Ports []string `json:"ports"`
+	}
+
+	if len(ports) < 1 {
+		return errors.New("at least one port is required")
+	}
+
+	bytes, err := json.Marshal(wrapper{IP: ip, Alias: alias, Ports: ports})
+	if err != nil {
+		return
+	}
+
+	_, err = c.query(ctx, fmt.Sprintf("nodes/%d/allocations", nodeID), "POST", bytes)
+	return
+}
+
+// DeleteAllocation removes an allocation from a node. Allocations assigned to a server can't be deleted.
+func (c *ApplicationCredentials) DeleteAllocation(nodeID, allocationID int) error {
+	return c.DeleteAllocationContext(context.Background(), nodeID, allocationID)
+}
+
+// DeleteAllocationContext is DeleteAllocation with a context
+func (c *ApplicationCredentials) DeleteAllocationContext(ctx context.Context, nodeID, allocationID int) (err error) {
+	_, err = c.query(ctx, fmt.Sprintf("nodes/%d/allocations/%d", nodeID, allocationID), "DELETE", nil)
+	return
+}
+
+// FindFreeAllocation searches the node for the first allocation not assigned to any server. If every allocation
+// is taken ErrNoFreeAllocation is returned.
+func (c *ApplicationCredentials) FindFreeAllocation(nodeID int) (*NodeAllocation, error) {
+	return c.FindFreeAllocationContext(context.Background(), nodeID)
+}
+
+// FindFreeAllocationContext is FindFreeAllocation with a context
+func (c *ApplicationCredentials) FindFreeAllocationContext(ctx context.Context, nodeID int) (*NodeAllocation, error) {
+	it := c.AllocationsIterContext(ctx, nodeID, nil)
+	for it.Next() {
+		if !it.Value().Assigned {
+			return it.Value(), nil
+		}
+	}
+
+	if it.Err() != nil {
+		return nil, it.Err()
+	}
+
+	return nil, ErrNoFreeAllocation
+}
@@ -0,0 +1,184 @@
+package fossil
+
+import (
+	"context"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+)
+
+//***** Testing *****//
+
+func TestApplicationCredentials_GetAllocations(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/application/nodes/1/allocations"
+		if expectURL != url {
+			t.Errorf("Request url does not match expected: %s", url)
+		}
+
+		res := `{
+		  "object": "list",
+		  "data": [
+			{
+			  "object": "allocation",
+			  "attributes": {
+				"id": 1,
+				"ip": "172.18.0.2",
+				"alias": null,
+				"port": 25565,
+				"notes": null,
+				"assigned": true
+			  }
+			},
+			{
+			  "object": "allocation",
+			  "attributes": {
+				"id": 2,
+				"ip": "172.18.0.2",
+				"alias": "game.example.com",
+				"port": 25566,
+				"notes": "Spare",
+				"assigned": false
+			  }
+			}
+		  ],
+		  "meta": {
+			"pagination": {
+			  "total": 2,
+			  "count": 2,
+			  "per_page": 50,
+			  "current_page": 1,
+			  "total_pages": 1,
+			  "links": {}
+			}
+		  }
+		}`
+
+		return []byte(res), nil
+	}
+
+	a := NewApplication("https://example.com", "")
+
+	expect := []*NodeAllocation{
+		{ID: 1, IP: "172.18.0.2", Port: 25565, Assigned: true},
+		{ID: 2, IP: "172.18.0.2", Alias: "game.example.com", Port: 25566, Notes: "Spare"},
+	}
+
+	got, err := a.GetAllocations(1)
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	if !cmp.Equal(got, expect) {
+		t.Errorf("Unexpected response: %s", cmp.Diff(got, expect))
+	}
+}
+
+func TestApplicationCredentials_CreateAllocations(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/application/nodes/1/allocations"
+		if expectURL != url || method != "POST" {
+			t.Errorf("Request does not match expected: %s %s", method, url)
+		}
+
+		expectBody := `{"ip":"172.18.0.2","ports":["25565","25570-25600"]}`
+		if expectBody != string(data) {
+			t.Errorf("Request data does not match expected: %s", string(data))
+		}
+
+		return nil, nil
+	}
+
+	a := NewApplication("https://example.com", "")
+
+	err := a.CreateAllocations(1, "172.18.0.2", "", "25565", PortRange(25570, 25600))
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	err = a.CreateAllocations(1, "172.18.0.2", "")
+	if err == nil {
+		t.Error("Expected an error when no ports are given")
+	}
+}
+
+func TestApplicationCredentials_DeleteAllocation(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/application/nodes/1/allocations/2"
+		if expectURL != url || method != "DELETE" {
+			t.Errorf("Request does not match expected: %s %s", method, url)
+		}
+
+		return nil, nil
+	}
+
+	a := NewApplication("https://example.com", "")
+
+	err := a.DeleteAllocation(1, 2)
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+}
+
+func TestApplicationCredentials_FindFreeAllocation(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		res := `{
+		  "object": "list",
+		  "data": [
+			{
+			  "object": "allocation",
+			  "attributes": {
+				"id": 1,
+				"ip": "172.18.0.2",
+				"alias": null,
+				"port": 25565,
+				"notes": null,
+				"assigned": true
+			  }
+			},
+			{
+			  "object": "allocation",
+			  "attributes": {
+				"id": 2,
+				"ip": "172.18.0.2",
+				"alias": "game.example.com",
+				"port": 25566,
+				"notes": "Spare",
+				"assigned": false
+			  }
+			}
+		  ],
+		  "meta": {
+			"pagination": {
+			  "total": 2,
+			  "count": 2,
+			  "per_page": 50,
+			  "current_page": 1,
+			  "total_pages": 1,
+			  "links": {}
+			}
+		  }
+		}`
+
+		return []byte(res), nil
+	}
+
+	a := NewApplication("https://example.com", "")
+
+	got, err := a.FindFreeAllocation(1)
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	if got.ID != 2 {
+		t.Errorf("Unexpected allocation: %d", got.ID)
+	}
+
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		return []byte(`{"object":"list","data":[]}`), nil
+	}
+
+	_, err = a.FindFreeAllocation(1)
+	if err != ErrNoFreeAllocation {
+		t.Errorf("Expected ErrNoFreeAllocation, got: %v", err)
+	}
+}
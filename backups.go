@@ -0,0 +1,286 @@
+package fossil
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+// backupPollInterval is used by WaitForBackup when no interval is given
+const backupPollInterval = 5 * time.Second
+
+//***** Structures *****//
+
+// Backup represents a backup of a server's files
+type Backup struct {
+	UUID         string     `json:"uuid"`
+	Name         string     `json:"name"`
+	IgnoredFiles []string   `json:"ignored_files"`
+	Checksum     string     `json:"checksum"`
+	Bytes        int64      `json:"bytes"`
+	IsSuccessful bool       `json:"is_successful"`
+	IsLocked     bool       `json:"is_locked"` // Locked backups can't be deleted
+	CreatedAt    time.Time  `json:"created_at"`
+	CompletedAt  *time.Time `json:"completed_at"` // Nil while the backup is in progress
+}
+
+// ErrBackupFailed is returned by WaitForBackup when the backup finished without success
+var ErrBackupFailed = errors.New("backup failed")
+
+//***** Helpers *****//
+
+// Completed reports whether the backup finished, successfully or not
+func (b *Backup) Completed() bool {
+	return b.CompletedAt != nil
+}
+
+//***** String *****//
+
+func (b *Backup) String() string {
+	return b.Name
+}
+
+//***** Pagination *****//
+
+// BackupIterator lazily walks the backups of a server, fetching the pages as needed
+type BackupIterator struct {
+	pager
+	backup *Backup
+}
+
+// Next advances to the next backup, returning false when there are no more or an error occurred
+func (it *BackupIterator) Next() bool {
+	var v Backup
+	if !it.next(&v) {
+		return false
+	}
+
+	it.backup = &v
+	return true
+}
+
+// Value returns the current backup
+func (it *BackupIterator) Value() *Backup {
+	return it.backup
+}
+
+//***** Requests *****//
+
+// ListBackups fetches all the backups of a server
+func (c *ClientCredentials) ListBackups(id string, opts ...*ListOptions) ([]*Backup, error) {
+	return c.ListBackupsContext(context.Background(), id, opts...)
+}
+
+// ListBackupsContext is ListBackups with a context
+func (c *ClientCredentials) ListBackupsContext(ctx context.Context, id string,
+	opts ...*ListOptions) (backups []*Backup, err error) {
+	it := c.BackupsIterContext(ctx, id, listOptions(opts))
+	for it.Next() {
+		backups = append(backups, it.Value())
+	}
+
+	return backups, it.Err()
+}
+
+// BackupsIter returns an iterator over the backups of a server. The pages are fetched as they are needed.
+func (c *ClientCredentials) BackupsIter(id string, opts *ListOptions) *BackupIterator {
+	return c.BackupsIterContext(context.Background(), id, opts)
+}
+
+// BackupsIterContext is BackupsIter with a context
+func (c *ClientCredentials) BackupsIterContext(ctx context.Context, id string, opts *ListOptions) *BackupIterator {
+	return &BackupIterator{pager: newPager(ctx, (*Credentials)(c), c.endpointURL("servers/"+id+"/backups"), opts)}
+}
+
+// CreateBackup starts a new backup of the server. The ignored files follow the .gitignore syntax, and locked
+// backups can't be deleted until unlocked. The backup runs in the background, see WaitForBackup.
+func (c *ClientCredentials) CreateBackup(id, name string, ignored []string, locked bool) (*Backup, error) {
+	return c.CreateBackupContext(context.Background(), id, name, ignored, locked)
+}
+
+// CreateBackupContext is CreateBackup with a context
+func (c *ClientCredentials) CreateBackupContext(ctx context.Context, id, name string, ignored []string,
+	locked bool) (backup *Backup, err error) {
+	type wrapper struct {
+		Name     string `json:"name,omitempty"`
+		Ignored  string `json:"ignored,omitempty"`
+		IsLocked bool   `json:"is_locked"`
+	}
+
+	rq, err := json.Marshal(wrapper{
+		Name:     name,
+		Ignored:  strings.Join(ignored, "\n"), // The API takes the ignored files one per line
+		IsLocked: locked,
+	})
+	if err != nil {
+		return
+	}
+
+	bytes, err := c.query(ctx, "servers/"+id+"/backups", "POST", rq)
+	if err != nil {
+		return
+	}
+
+	return decodeBackup(bytes)
+}
+
+// GetBackup fetches the backup with the given UUID
+func (c *ClientCredentials) GetBackup(id, backupID string) (*Backup, error) {
+	return c.GetBackupContext(context.Background(), id, backupID)
+}
+
+// GetBackupContext is GetBackup with a context
+func (c *ClientCredentials) GetBackupContext(ctx context.Context, id, backupID string) (*Backup, error) {
+	bytes, err := c.query(ctx, "servers/"+id+"/backups/"+backupID, "GET", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeBackup(bytes)
+}
+
+// GetBackupDownloadURL fetches a signed URL to download a backup. The URL is only valid for a short time.
+func (c *ClientCredentials) GetBackupDownloadURL(id, backupID string) (string, error) {
+	return c.GetBackupDownloadURLContext(context.Background(), id, backupID)
+}
+
+// GetBackupDownloadURLContext is GetBackupDownloadURL with a context
+func (c *ClientCredentials) GetBackupDownloadURLContext(ctx context.Context, id, backupID string) (string, error) {
+	return c.signedURL(ctx, "servers/"+id+"/backups/"+backupID+"/download")
+}
+
+// DownloadBackup streams a backup into w, without buffering it in memory, and returns the amount of bytes written.
+// The progress function is optional.
+func (c *ClientCredentials) DownloadBackup(id, backupID string, w io.Writer, progress ProgressFunc) (int64, error) {
+	return c.DownloadBackupContext(context.Background(), id, backupID, w, progress)
+}
+
+// DownloadBackupContext is DownloadBackup with a context
+func (c *ClientCredentials) DownloadBackupContext(ctx context.Context, id, backupID string, w io.Writer,
+	progress ProgressFunc) (int64, error) {
+	target, err := c.GetBackupDownloadURLContext(ctx, id, backupID)
+	if err != nil {
+		return 0, err
+	}
+
+	return c.download(ctx, target, w, progress)
+}
+
+// LockBackup prevents a backup from being deleted
+func (c *ClientCredentials) LockBackup(id, backupID string) (*Backup, error) {
+	return c.LockBackupContext(context.Background(), id, backupID)
+}
+
+// LockBackupContext is LockBackup with a context
+func (c *ClientCredentials) LockBackupContext(ctx context.Context, id, backupID string) (*Backup, error) {
+	return c.setBackupLock(ctx, id, backupID, true)
+}
+
+// UnlockBackup allows a locked backup to be deleted again
+func (c *ClientCredentials) UnlockBackup(id, backupID string) (*Backup, error) {
+	return c.UnlockBackupContext(context.Background(), id, backupID)
+}
+
+// UnlockBackupContext is UnlockBackup with a context
+func (c *ClientCredentials) UnlockBackupContext(ctx context.Context, id, backupID string) (*Backup, error) {
+	return c.setBackupLock(ctx, id, backupID, false)
+}
+
+// setBackupLock sets the lock of a backup. The API only allows toggling it, so the current state is checked first.
+func (c *ClientCredentials) setBackupLock(ctx context.Context, id, backupID string, locked bool) (*Backup, error) {
+	backup, err := c.GetBackupContext(ctx, id, backupID)
+	if err != nil {
+		return nil, err
+	}
+
+	if backup.IsLocked == locked {
+		return backup, nil
+	}
+
+	bytes, err := c.query(ctx, "servers/"+id+"/backups/"+backupID+"/lock", "POST", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeBackup(bytes)
+}
+
+// RestoreBackup restores the files of a backup into the server. If truncate is set all the files of the server
+// are deleted before restoring.
+func (c *ClientCredentials) RestoreBackup(id, backupID string, truncate bool) error {
+	return c.RestoreBackupContext(context.Background(), id, backupID, truncate)
+}
+
+// RestoreBackupContext is RestoreBackup with a context
+func (c *ClientCredentials) RestoreBackupContext(ctx context.Context, id, backupID string, truncate bool) (err error) {
+	type wrapper struct {
+		Truncate bool `json:"truncate"`
+	}
+
+	bytes, err := json.Marshal(wrapper{Truncate: truncate})
+	if err != nil {
+		return
+	}
+
+	_, err = c.query(ctx, "servers/"+id+"/backups/"+backupID+"/restore", "POST", bytes)
+	return
+}
+
+// DeleteBackup deletes a backup. Locked backups must be unlocked first.
+func (c *ClientCredentials) DeleteBackup(id, backupID string) error {
+	return c.DeleteBackupContext(context.Background(), id, backupID)
+}
+
+// DeleteBackupContext is DeleteBackup with a context
+func (c *ClientCredentials) DeleteBackupContext(ctx context.Context, id, backupID string) (err error) {
+	_, err = c.query(ctx, "servers/"+id+"/backups/"+backupID, "DELETE", nil)
+	return
+}
+
+// WaitForBackup polls the backup every interval until it completes or the context is done. A non-positive interval
+// polls every 5 seconds. If the backup finished without success the backup is returned along with ErrBackupFailed.
+func (c *ClientCredentials) WaitForBackup(ctx context.Context, id, backupID string,
+	interval time.Duration) (*Backup, error) {
+	if interval <= 0 {
+		interval = backupPollInterval
+	}
+
+	for {
+		backup, err := c.GetBackupContext(ctx, id, backupID)
+		if err != nil {
+			return nil, err
+		}
+
+		if backup.Completed() {
+			if !backup.IsSuccessful {
+				return backup, ErrBackupFailed
+			}
+
+			return backup, nil
+		}
+
+		err = sleep(ctx, interval)
+		if err != nil {
+			return backup, err
+		}
+	}
+}
+
+//***** Converters *****//
+
+// decodeBackup parses a single backup response
+func decodeBackup(bytes []byte) (*Backup, error) {
+	var wrapper struct {
+		Backup *Backup `json:"attributes"`
+	}
+
+	err := json.Unmarshal(bytes, &wrapper)
+	if err != nil {
+		return nil, err
+	}
+
+	return wrapper.Backup, nil
+}
@@ -0,0 +1,284 @@
+package fossil
+
+import (
+	"bytes"
+	"context"
+	"github.com/google/go-cmp/cmp"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+//***** Testing *****//
+
+func TestClientCredentials_ListBackups(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/backups"
+		if expectURL != url {
+			t.Errorf("Request url does not match expected: %s", url)
+		}
+
+		return []byte(`{"object":"list","data":[{
+		  "object": "backup",
+		  "attributes": {
+			"uuid": "904df120-a66f-4375-a4a7-0b1c7a4e6f23",
+			"is_successful": true,
+			"is_locked": false,
+			"name": "Before update",
+			"ignored_files": ["*.log"],
+			"checksum": "sha1:ed34a1f3e9e2fc6f9a5d4c17ed3b6b0a5d3c3c38",
+			"bytes": 5242880,
+			"created_at": "2020-11-02T12:21:08+00:00",
+			"completed_at": "2020-11-02T12:21:40+00:00"
+		  }
+		}],
+			"meta":{"pagination":{"total":1,"count":1,"per_page":20,"current_page":1,"total_pages":1}}}`), nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	got, err := c.ListBackups("1a7ce997")
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	created, _ := time.Parse(time.RFC3339, "2020-11-02T12:21:08+00:00")
+	completed, _ := time.Parse(time.RFC3339, "2020-11-02T12:21:40+00:00")
+
+	expect := []*Backup{
+		{
+			UUID:         "904df120-a66f-4375-a4a7-0b1c7a4e6f23",
+			Name:         "Before update",
+			IgnoredFiles: []string{"*.log"},
+			Checksum:     "sha1:ed34a1f3e9e2fc6f9a5d4c17ed3b6b0a5d3c3c38",
+			Bytes:        5242880,
+			IsSuccessful: true,
+			CreatedAt:    created,
+			CompletedAt:  &completed,
+		},
+	}
+
+	if !cmp.Equal(got, expect) {
+		t.Errorf("Unexpected response: %s", cmp.Diff(got, expect))
+	}
+}
+
+func TestClientCredentials_CreateBackup(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/backups"
+		if expectURL != url || method != "POST" {
+			t.Errorf("Request url does not match expected: %s %s", method, url)
+		}
+
+		expectBody := `{"name":"Before update","ignored":"*.log\ncache/","is_locked":true}`
+		if expectBody != string(data) {
+			t.Errorf("Request body does not match expected: %s", data)
+		}
+
+		res := `{
+		  "object": "backup",
+		  "attributes": {
+			"uuid": "904df120-a66f-4375-a4a7-0b1c7a4e6f23",
+			"is_successful": true,
+			"is_locked": false,
+			"name": "Before update",
+			"ignored_files": ["*.log"],
+			"checksum": "sha1:ed34a1f3e9e2fc6f9a5d4c17ed3b6b0a5d3c3c38",
+			"bytes": 5242880,
+			"created_at": "2020-11-02T12:21:08+00:00",
+			"completed_at": "2020-11-02T12:21:40+00:00"
+		  }
+		}`
+
+		return []byte(res), nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	got, err := c.CreateBackup("1a7ce997", "Before update", []string{"*.log", "cache/"}, true)
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	created, _ := time.Parse(time.RFC3339, "2020-11-02T12:21:08+00:00")
+	completed, _ := time.Parse(time.RFC3339, "2020-11-02T12:21:40+00:00")
+
+	expect := &Backup{
+		UUID:         "904df120-a66f-4375-a4a7-0b1c7a4e6f23",
+		Name:         "Before update",
+		IgnoredFiles: []string{"*.log"},
+		Checksum:     "sha1:ed34a1f3e9e2fc6f9a5d4c17ed3b6b0a5d3c3c38",
+		Bytes:        5242880,
+		IsSuccessful: true,
+		CreatedAt:    created,
+		CompletedAt:  &completed,
+	}
+
+	if !cmp.Equal(got, expect) {
+		t.Errorf("Unexpected response: %s", cmp.Diff(got, expect))
+	}
+}
+
+func TestClientCredentials_LockBackup(t *testing.T) {
+	var toggled bool
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		res := `{
+		  "object": "backup",
+		  "attributes": {
+			"uuid": "904df120-a66f-4375-a4a7-0b1c7a4e6f23",
+			"is_successful": true,
+			"is_locked": false,
+			"name": "Before update",
+			"ignored_files": ["*.log"],
+			"checksum": "sha1:ed34a1f3e9e2fc6f9a5d4c17ed3b6b0a5d3c3c38",
+			"bytes": 5242880,
+			"created_at": "2020-11-02T12:21:08+00:00",
+			"completed_at": "2020-11-02T12:21:40+00:00"
+		  }
+		}`
+
+		switch url {
+		case "https://example.com/api/client/servers/1a7ce997/backups/904df120-a66f-4375-a4a7-0b1c7a4e6f23":
+			return []byte(res), nil
+		case "https://example.com/api/client/servers/1a7ce997/backups/904df120-a66f-4375-a4a7-0b1c7a4e6f23/lock":
+			toggled = true
+			return bytes.Replace([]byte(res), []byte(`"is_locked": false`), []byte(`"is_locked": true`), 1), nil
+		}
+
+		t.Errorf("Unexpected request url: %s", url)
+		return nil, nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	// Already unlocked, so the lock must not be toggled
+	got, err := c.UnlockBackup("1a7ce997", "904df120-a66f-4375-a4a7-0b1c7a4e6f23")
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	if toggled || got.IsLocked {
+		t.Error("An unlocked backup was toggled")
+	}
+
+	got, err = c.LockBackup("1a7ce997", "904df120-a66f-4375-a4a7-0b1c7a4e6f23")
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	if !toggled || !got.IsLocked {
+		t.Error("The backup was not locked")
+	}
+}
+
+func TestClientCredentials_RestoreBackup(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/backups/904df120/restore"
+		if expectURL != url {
+			t.Errorf("Request url does not match expected: %s", url)
+		}
+
+		if string(data) != `{"truncate":true}` {
+			t.Errorf("Request body does not match expected: %s", data)
+		}
+
+		return nil, nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	err := c.RestoreBackup("1a7ce997", "904df120", true)
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+}
+
+func TestClientCredentials_DeleteBackup(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/backups/904df120"
+		if expectURL != url || method != "DELETE" {
+			t.Errorf("Request url does not match expected: %s %s", method, url)
+		}
+
+		return nil, nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	err := c.DeleteBackup("1a7ce997", "904df120")
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+}
+
+func TestClientCredentials_DownloadBackup(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("backup contents"))
+	}))
+	defer srv.Close()
+
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/backups/904df120/download"
+		if expectURL != url {
+			t.Errorf("Request url does not match expected: %s", url)
+		}
+
+		return []byte(`{"object":"signed_url","attributes":{"url":"` + srv.URL + `/download/backup?token=abc"}}`), nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	var buf bytes.Buffer
+	n, err := c.DownloadBackup("1a7ce997", "904df120", &buf, nil)
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	if n != 15 || buf.String() != "backup contents" {
+		t.Errorf("Unexpected download: %d bytes, %s", n, buf.String())
+	}
+}
+
+func TestClientCredentials_WaitForBackup(t *testing.T) {
+	var polls int
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		polls++
+		if polls < 3 {
+			return []byte(`{"object":"backup","attributes":{"uuid":"904df120","completed_at":null}}`), nil
+		}
+
+		return []byte(`{"object":"backup","attributes":{"uuid":"904df120","is_successful":false,
+			"completed_at":"2020-11-02T12:21:40+00:00"}}`), nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	got, err := c.WaitForBackup(context.Background(), "1a7ce997", "904df120", time.Millisecond)
+	if err != ErrBackupFailed {
+		t.Errorf("Expected ErrBackupFailed, got: %v", err)
+	}
+
+	if polls != 3 || got == nil || !got.Completed() {
+		t.Errorf("Unexpected polling: %d polls, %+v", polls, got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	polls = 0
+	_, err = c.WaitForBackup(ctx, "1a7ce997", "904df120", time.Hour)
+	if err != context.Canceled {
+		t.Errorf("Expected a canceled context error, got: %v", err)
+	}
+
+	// A missing interval must not poll the panel back to back
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	polls = 0
+	_, err = c.WaitForBackup(ctx, "1a7ce997", "904df120", 0)
+	if err != context.DeadlineExceeded || polls != 1 {
+		t.Errorf("Unexpected polling: %d polls, %v", polls, err)
+	}
+}
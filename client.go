@@ -1,20 +1,27 @@
 package fossil
 
 import (
+	"context"
 	"encoding/json"
+	"sync/atomic"
 )
 
 //***** Requests *****//
 
 // GetServer fetches the server with the given ID if it exists
-func (c *ClientCredentials) GetServer(id string) (sv *ClientServer, err error) {
-	bytes, err := c.query("servers/"+id+"?include=allocations", "GET", nil)
+func (c *ClientCredentials) GetServer(id string) (*ClientServer, error) {
+	return c.GetServerContext(context.Background(), id)
+}
+
+// GetServerContext is GetServer with a context
+func (c *ClientCredentials) GetServerContext(ctx context.Context, id string) (sv *ClientServer, err error) {
+	bytes, err := c.query(ctx, "servers/"+id+"?include=allocations", "GET", nil)
 	if err != nil {
 		return
 	}
 
 	var wrapper struct {
-		Server jsonServer `json:"attributes"`
+		Server jsonClientServer `json:"attributes"`
 	}
 
 	err = json.Unmarshal(bytes, &wrapper)
@@ -25,54 +32,112 @@ func (c *ClientCredentials) GetServer(id string) (sv *ClientServer, err error) {
 	return wrapper.Server.asClientServer(), nil
 }
 
-// GetServers fetches all the servers of the client
-func (c *ClientCredentials) GetServers() (svs []*ClientServer, err error) {
-	bytes, err := c.query("?include=allocations", "GET", nil)
-	if err != nil {
-		return
+// GetServers fetches all the servers of the client. The results can be optionally filtered and sorted with
+// ListOptions.
+func (c *ClientCredentials) GetServers(opts ...*ListOptions) ([]*ClientServer, error) {
+	return c.GetServersContext(context.Background(), opts...)
+}
+
+// GetServersContext is GetServers with a context
+func (c *ClientCredentials) GetServersContext(ctx context.Context, opts ...*ListOptions) (svs []*ClientServer, err error) {
+	it := c.ServersIterContext(ctx, listOptions(opts))
+	for it.Next() {
+		svs = append(svs, it.Value())
 	}
 
-	// Get the initial page
-	var page jsonServerPage
-	err = json.Unmarshal(bytes, &page)
-	if err != nil {
-		return
+	return svs, it.Err()
+}
+
+// ServersIter returns an iterator over the servers of the client. The pages are fetched as they are needed.
+func (c *ClientCredentials) ServersIter(opts *ListOptions) *ClientServerIterator {
+	return c.ServersIterContext(context.Background(), opts)
+}
+
+// ServersIterContext is ServersIter with a context
+func (c *ClientCredentials) ServersIterContext(ctx context.Context, opts *ListOptions) *ClientServerIterator {
+	return &ClientServerIterator{pager: newPager(ctx, (*Credentials)(c), c.endpointURL("?include=allocations"), opts)}
+}
+
+// GetServerStatus fetches the server's status and usage. Newer panels are queried through the resources
+// endpoint, and older ones through the utilization endpoint. The one the panel supports is detected on the first
+// call and remembered.
+func (c *ClientCredentials) GetServerStatus(id string) (*ServerStatus, error) {
+	return c.GetServerStatusContext(context.Background(), id)
+}
+
+// GetServerStatusContext is GetServerStatus with a context
+func (c *ClientCredentials) GetServerStatusContext(ctx context.Context, id string) (*ServerStatus, error) {
+	api := atomic.LoadInt32(&c.statusAPI)
+	if api == statusAPIUtilization {
+		return c.getUtilization(ctx, id)
 	}
 
-	// Search for the remaining pages if present
-	pages, err := page.getAll(c.Token)
+	ss, err := c.getResources(ctx, id)
+	if err == nil {
+		atomic.StoreInt32(&c.statusAPI, statusAPIResources)
+		return ss, nil
+	}
+
+	if api == statusAPIResources || !IsNotFound(err) {
+		return nil, err
+	}
+
+	// Either the panel predates the resources endpoint or the server doesn't exist, which the utilization
+	// endpoint tells apart
+	ss, uErr := c.getUtilization(ctx, id)
+	if uErr != nil {
+		return nil, err
+	}
+
+	atomic.StoreInt32(&c.statusAPI, statusAPIUtilization)
+	return ss, nil
+}
+
+// getResources fetches the server's status and usage from the resources endpoint
+func (c *ClientCredentials) getResources(ctx context.Context, id string) (*ServerStatus, error) {
+	bytes, err := c.query(ctx, "servers/"+id+"/resources", "GET", nil)
 	if err != nil {
-		return
+		return nil, err
 	}
 
-	for _, page := range pages {
-		svs = append(svs, page.asClientServers()...)
+	var wrapper struct {
+		Resources jsonResources `json:"attributes"`
 	}
 
-	return
+	err = json.Unmarshal(bytes, &wrapper)
+	if err != nil {
+		return nil, err
+	}
+
+	return wrapper.Resources.asServerStatus(), nil
 }
 
-// GetServerStatus fetches the server's status and usage
-func (c *ClientCredentials) GetServerStatus(id string) (ss *ServerStatus, err error) {
-	bytes, err := c.query("servers/"+id+"/utilization", "GET", nil)
+// getUtilization fetches the server's status and usage from the utilization endpoint of older panels
+func (c *ClientCredentials) getUtilization(ctx context.Context, id string) (*ServerStatus, error) {
+	bytes, err := c.query(ctx, "servers/"+id+"/utilization", "GET", nil)
 	if err != nil {
-		return
+		return nil, err
 	}
 
 	var wrapper struct {
-		Utilization *ServerStatus `json:"attributes"`
+		Utilization jsonUtilization `json:"attributes"`
 	}
 
 	err = json.Unmarshal(bytes, &wrapper)
 	if err != nil {
-		return
+		return nil, err
 	}
 
-	return wrapper.Utilization, nil
+	return wrapper.Utilization.asServerStatus(), nil
 }
 
 // ExecuteCommand allows the execution of a console command on the specified server
-func (c *ClientCredentials) ExecuteCommand(id string, cmd string) (err error) {
+func (c *ClientCredentials) ExecuteCommand(id string, cmd string) error {
+	return c.ExecuteCommandContext(context.Background(), id, cmd)
+}
+
+// ExecuteCommandContext is ExecuteCommand with a context
+func (c *ClientCredentials) ExecuteCommandContext(ctx context.Context, id string, cmd string) (err error) {
 	type wrapper struct {
 		Command string `json:"command"`
 	}
@@ -84,7 +149,7 @@ func (c *ClientCredentials) ExecuteCommand(id string, cmd string) (err error) {
 		return
 	}
 
-	_, err = c.query("servers/"+id+"/command", "POST", rq)
+	_, err = c.query(ctx, "servers/"+id+"/command", "POST", rq)
 	if err != nil {
 		return
 	}
@@ -94,9 +159,14 @@ func (c *ClientCredentials) ExecuteCommand(id string, cmd string) (err error) {
 
 // SetPowerState changes the power state of a server. Will result in error if the server is already in that state
 // or is unable to change state.
-func (c *ClientCredentials) SetPowerState(id string, state string) (err error) {
+func (c *ClientCredentials) SetPowerState(id string, state PowerSignal) error {
+	return c.SetPowerStateContext(context.Background(), id, state)
+}
+
+// SetPowerStateContext is SetPowerState with a context
+func (c *ClientCredentials) SetPowerStateContext(ctx context.Context, id string, state PowerSignal) (err error) {
 	type wrapper struct {
-		Signal string `json:"signal"`
+		Signal PowerSignal `json:"signal"`
 	}
 
 	signalWrapper := wrapper{Signal: state}
@@ -106,10 +176,63 @@ func (c *ClientCredentials) SetPowerState(id string, state string) (err error) {
 		return
 	}
 
-	_, err = c.query("servers/"+id+"/power", "POST", rq)
+	_, err = c.query(ctx, "servers/"+id+"/power", "POST", rq)
 	if err != nil {
 		return
 	}
 
 	return nil
 }
+
+// RenameServer changes the name and description of a server. An empty description leaves the current one.
+func (c *ClientCredentials) RenameServer(id, name, description string) error {
+	return c.RenameServerContext(context.Background(), id, name, description)
+}
+
+// RenameServerContext is RenameServer with a context
+func (c *ClientCredentials) RenameServerContext(ctx context.Context, id, name, description string) (err error) {
+	type wrapper struct {
+		Name        string `json:"name"`
+		Description string `json:"description,omitempty"`
+	}
+
+	rq, err := json.Marshal(wrapper{Name: name, Description: description})
+	if err != nil {
+		return
+	}
+
+	_, err = c.query(ctx, "servers/"+id+"/settings/rename", "POST", rq)
+	return
+}
+
+// ReinstallServer runs the egg's install script again. Depending on the egg the server files may be overwritten.
+func (c *ClientCredentials) ReinstallServer(id string) error {
+	return c.ReinstallServerContext(context.Background(), id)
+}
+
+// ReinstallServerContext is ReinstallServer with a context
+func (c *ClientCredentials) ReinstallServerContext(ctx context.Context, id string) (err error) {
+	_, err = c.query(ctx, "servers/"+id+"/settings/reinstall", "POST", nil)
+	return
+}
+
+// SetDockerImage switches the Docker image of a server. The image must be one of the allowed by the egg, see
+// GetStartup. The change takes effect on the next restart.
+func (c *ClientCredentials) SetDockerImage(id, image string) error {
+	return c.SetDockerImageContext(context.Background(), id, image)
+}
+
+// SetDockerImageContext is SetDockerImage with a context
+func (c *ClientCredentials) SetDockerImageContext(ctx context.Context, id, image string) (err error) {
+	type wrapper struct {
+		DockerImage string `json:"docker_image"`
+	}
+
+	rq, err := json.Marshal(wrapper{DockerImage: image})
+	if err != nil {
+		return
+	}
+
+	_, err = c.query(ctx, "servers/"+id+"/settings/docker-image", "PUT", rq)
+	return
+}
@@ -1,14 +1,16 @@
 package fossil
 
 import (
+	"context"
 	"github.com/google/go-cmp/cmp"
 	"testing"
+	"time"
 )
 
 //***** Testing *****//
 
 func TestClientCredentials_GetServers(t *testing.T) {
-	query = func(url, token, method string, data []byte) ([]byte, error) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
 		// The response is provided by the Pterodactyl API Documentation. The meta.pagination.links parameter
 		// has been modified from [] to {} since all analyzed responses do not, respond with an array but an
 		// empty object. See: https://github.com/parkervcp/crocgodyl/issues/8
@@ -83,7 +85,7 @@ func TestClientCredentials_GetServers(t *testing.T) {
 }
 
 func TestClientCredentials_GetServer(t *testing.T) {
-	query = func(url, token, method string, data []byte) ([]byte, error) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
 		res := `{
 		   "object":"server",
 		   "attributes":{
@@ -138,7 +140,19 @@ func TestClientCredentials_GetServer(t *testing.T) {
 }
 
 func TestClientCredentials_GetStatus(t *testing.T) {
-	query = func(url, token, method string, data []byte) ([]byte, error) {
+	var resourcesQueries int
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		// Older panels don't have the resources endpoint
+		if url == "https://example.com/api/client/servers/1a7ce997/resources" {
+			resourcesQueries++
+			return nil, &APIError{StatusCode: 404}
+		}
+
+		expectURL := "https://example.com/api/client/servers/1a7ce997/utilization"
+		if expectURL != url {
+			t.Errorf("Request url does not match expected: %s", url)
+		}
+
 		res := `{
 		   "object":"stats",
 		   "attributes":{
@@ -179,13 +193,14 @@ func TestClientCredentials_GetStatus(t *testing.T) {
 		return []byte(res), nil
 	}
 
-	c := NewClient("", "")
+	c := NewClient("https://example.com", "")
 
+	// The megabytes given by older panels are converted to bytes
 	expect := &ServerStatus{
-		State: "on",
+		State: StateRunning,
 		Memory: Memory{
-			Used:  375,
-			Limit: 1024,
+			Used:  375 * 1024 * 1024,
+			Limit: 1024 * 1024 * 1024,
 		},
 		CPU: CPU{
 			Current: 1.522,
@@ -210,24 +225,132 @@ func TestClientCredentials_GetStatus(t *testing.T) {
 			Limit: 200,
 		},
 		Disk: Disk{
-			Used:  119,
-			Limit: 5000,
+			Used:  119 * 1024 * 1024,
+			Limit: 5000 * 1024 * 1024,
 		},
 		Players: Players{},
 	}
 
-	got, err := c.GetServerStatus("")
+	got, err := c.GetServerStatus("1a7ce997")
 	if err != nil {
 		t.Errorf("Error: %s", err.Error())
 	}
 
-	if cmp.Equal(got, expect) {
-		t.Error("Unexpected response")
+	if !cmp.Equal(got, expect) {
+		t.Errorf("Unexpected response: %s", cmp.Diff(got, expect))
+	}
+
+	// The missing endpoint must be remembered
+	_, err = c.GetServerStatus("1a7ce997")
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	if resourcesQueries != 1 {
+		t.Errorf("The resources endpoint was queried %d times", resourcesQueries)
+	}
+}
+
+func TestClientCredentials_GetStatus_Resources(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/resources"
+		if expectURL != url {
+			t.Errorf("Request url does not match expected: %s", url)
+		}
+
+		res := `{
+		  "object": "stats",
+		  "attributes": {
+			"current_state": "starting",
+			"is_suspended": false,
+			"resources": {
+			  "memory_bytes": 588701696,
+			  "cpu_absolute": 19.385,
+			  "disk_bytes": 130156361,
+			  "network_rx_bytes": 694220,
+			  "network_tx_bytes": 337090,
+			  "uptime": 61250
+			}
+		  }
+		}`
+
+		return []byte(res), nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	expect := &ServerStatus{
+		State:   StateStarting,
+		Memory:  Memory{Used: 588701696},
+		CPU:     CPU{Current: 19.385},
+		Disk:    Disk{Used: 130156361},
+		Network: Network{RxBytes: 694220, TxBytes: 337090},
+		Uptime:  61250 * time.Millisecond,
+	}
+
+	got, err := c.GetServerStatus("1a7ce997")
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	if !cmp.Equal(got, expect) {
+		t.Errorf("Unexpected response: %s", cmp.Diff(got, expect))
+	}
+}
+
+func TestClientCredentials_GetStatus_SameUnits(t *testing.T) {
+	// The same usage, 512 MB of memory and 2 GB of disk, as given by each endpoint
+	legacy := NewClient("https://example.com", "")
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		if url == "https://example.com/api/client/servers/1a7ce997/resources" {
+			return nil, &APIError{StatusCode: 404}
+		}
+
+		return []byte(`{"object":"stats","attributes":{"state":"on","memory":{"current":512},
+			"disk":{"current":2048}}}`), nil
+	}
+
+	old, err := legacy.GetServerStatus("1a7ce997")
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	current := NewClient("https://example.com", "")
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		return []byte(`{"object":"stats","attributes":{"current_state":"running",
+			"resources":{"memory_bytes":536870912,"disk_bytes":2147483648}}}`), nil
+	}
+
+	got, err := current.GetServerStatus("1a7ce997")
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	if !cmp.Equal(got, old) {
+		t.Errorf("Unexpected response: %s", cmp.Diff(got, old))
+	}
+}
+
+func TestClientCredentials_GetStatus_NotFound(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		return nil, &APIError{StatusCode: 404}
+	}
+
+	c := NewClient("https://example.com", "")
+
+	_, err := c.GetServerStatus("1a7ce997")
+	if !IsNotFound(err) {
+		t.Errorf("Expected a not found error, got: %v", err)
+	}
+
+	// A missing server must not be mistaken for an older panel
+	if c.statusAPI != statusAPIUnknown {
+		t.Error("The status endpoint was detected from a missing server")
 	}
 }
 
 func TestClientCredentials_ExecuteCommand(t *testing.T) {
-	query = func(url, token, method string, data []byte) ([]byte, error) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
 		expectBody := `{"command":"test"}`
 		expectURL := "https://example.com/api/client/servers/test_id/command"
 
@@ -251,7 +374,7 @@ func TestClientCredentials_ExecuteCommand(t *testing.T) {
 }
 
 func TestClientCredentials_SetPowerState(t *testing.T) {
-	query = func(url, token, method string, data []byte) ([]byte, error) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
 		expectBody := `{"signal":"start"}`
 		expectURL := "https://example.com/api/client/servers/test_id/power"
 
@@ -273,3 +396,178 @@ func TestClientCredentials_SetPowerState(t *testing.T) {
 		t.Errorf("Error: %s", err.Error())
 	}
 }
+
+func TestClientCredentials_GetServerContext(t *testing.T) {
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "test")
+
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		if ctx.Value(key{}) != "test" {
+			t.Error("Request context was not passed through")
+		}
+
+		return []byte(`{"object":"server","attributes":{}}`), nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	_, err := c.GetServerContext(ctx, "test_id")
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+}
+
+func TestClientCredentials_GetServer_Details(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997?include=allocations"
+		if expectURL != url {
+			t.Errorf("Request url does not match expected: %s", url)
+		}
+
+		res := `{
+		  "object": "server",
+		  "attributes": {
+			"server_owner": true,
+			"identifier": "1a7ce997",
+			"uuid": "1a7ce997-259b-452e-8b4e-cecc464142ca",
+			"name": "Survival",
+			"node": "Node 1",
+			"sftp_details": {
+			  "ip": "node1.example.com",
+			  "port": 2022
+			},
+			"description": "",
+			"limits": {
+			  "memory": 1024,
+			  "swap": 0,
+			  "disk": 5000,
+			  "io": 500,
+			  "cpu": 200
+			},
+			"docker_image": "ghcr.io/pterodactyl/yolks:java_17",
+			"feature_limits": {
+			  "databases": 5,
+			  "allocations": 5
+			},
+			"status": "installing",
+			"is_suspended": false,
+			"is_installing": true,
+			"relationships": {
+			  "allocations": {
+				"object": "list",
+				"data": [
+				  {
+					"object": "allocation",
+					"attributes": {
+					  "id": 1,
+					  "ip": "45.86.168.218",
+					  "ip_alias": null,
+					  "port": 25565,
+					  "notes": null,
+					  "is_default": true
+					}
+				  }
+				]
+			  }
+			}
+		  }
+		}`
+
+		return []byte(res), nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	expect := &ClientServer{
+		ID:          "1a7ce997",
+		UUID:        "1a7ce997-259b-452e-8b4e-cecc464142ca",
+		Name:        "Survival",
+		Node:        "Node 1",
+		SFTP:        SFTPDetails{IP: "node1.example.com", Port: 2022},
+		Status:      "installing",
+		DockerImage: "ghcr.io/pterodactyl/yolks:java_17",
+		Limits: Limits{
+			Memory:    1024,
+			Disk:      5000,
+			IO:        500,
+			CPU:       200,
+			Databases: 5,
+		},
+		AllocationDetails: []Allocation{{ID: 1, Primary: true, IP: "45.86.168.218", Port: 25565}},
+		IsOwner:           true,
+		IsInstalling:      true,
+	}
+
+	got, err := c.GetServer("1a7ce997")
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	if !cmp.Equal(got, expect) {
+		t.Errorf("Unexpected response: %s", cmp.Diff(got, expect))
+	}
+}
+
+func TestClientCredentials_RenameServer(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/settings/rename"
+		if expectURL != url || method != "POST" {
+			t.Errorf("Request url does not match expected: %s %s", method, url)
+		}
+
+		expectBody := `{"name":"Creative","description":"Creative world"}`
+		if expectBody != string(data) {
+			t.Errorf("Request body does not match expected: %s", data)
+		}
+
+		return nil, nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	err := c.RenameServer("1a7ce997", "Creative", "Creative world")
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+}
+
+func TestClientCredentials_ReinstallServer(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/settings/reinstall"
+		if expectURL != url || method != "POST" {
+			t.Errorf("Request url does not match expected: %s %s", method, url)
+		}
+
+		return nil, nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	err := c.ReinstallServer("1a7ce997")
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+}
+
+func TestClientCredentials_SetDockerImage(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/settings/docker-image"
+		if expectURL != url || method != "PUT" {
+			t.Errorf("Request url does not match expected: %s %s", method, url)
+		}
+
+		expectBody := `{"docker_image":"ghcr.io/pterodactyl/yolks:java_17"}`
+		if expectBody != string(data) {
+			t.Errorf("Request body does not match expected: %s", data)
+		}
+
+		return nil, nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	err := c.SetDockerImage("1a7ce997", "ghcr.io/pterodactyl/yolks:java_17")
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+}
@@ -0,0 +1,474 @@
+package fossil
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gorilla/websocket"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// consoleBuffer is the amount of events each console channel holds before new ones are dropped
+const consoleBuffer = 100
+
+//***** Structures *****//
+
+// WebsocketToken holds the credentials to connect to the websocket of a server. The token is short-lived, and
+// must be renewed while connected.
+type WebsocketToken struct {
+	Token  string `json:"token"`
+	Socket string `json:"socket"` // URL of the websocket on the node
+}
+
+// Console is a real-time connection to a server through the websocket of its node. The events are delivered
+// through the channels, which are closed when the console is. Unread channels don't block the console: once a
+// channel holds consoleBuffer events the new ones are dropped until it's read.
+type Console struct {
+	Output        <-chan string        // Console lines, including the daemon messages
+	Status        <-chan ServerState   // Power states, like StateStarting, StateRunning or StateOffline
+	Stats         <-chan *ConsoleStats // Resource usage, sent about every second while the server runs
+	InstallOutput <-chan string        // Lines of the install script
+	Errors        <-chan error         // Errors reported by the daemon, as *DaemonError
+
+	c      *ClientCredentials
+	id     string
+	conn   *websocket.Conn
+	ctx    context.Context
+	cancel context.CancelFunc
+	mu     sync.Mutex // The connection allows a single writer at a time
+
+	output        chan string
+	status        chan ServerState
+	stats         chan *ConsoleStats
+	installOutput chan string
+	errs          chan error
+
+	watchMu  sync.Mutex // Guards the state and the watchers
+	state    ServerState
+	watchers map[*stateWatch]struct{}
+	stopped  bool
+
+	done chan struct{}
+	err  error
+}
+
+// stateWatch receives the status changes and daemon errors of a console, for the waits of power.go
+type stateWatch struct {
+	states chan ServerState
+	errs   chan error
+}
+
+// ConsoleStats is the resource usage of a server, as sent through the console
+type ConsoleStats struct {
+	State            ServerState `json:"state"`
+	MemoryBytes      uint64      `json:"memory_bytes"`
+	MemoryLimitBytes uint64      `json:"memory_limit_bytes"`
+	CPUAbsolute      float64     `json:"cpu_absolute"` // Percentage, where 100 is a full core
+	DiskBytes        uint64      `json:"disk_bytes"`
+	Network          struct {
+		RxBytes uint64 `json:"rx_bytes"`
+		TxBytes uint64 `json:"tx_bytes"`
+	} `json:"network"`
+	Uptime int64 `json:"uptime"` // Milliseconds
+}
+
+// DaemonError is an error reported by the daemon through the console
+type DaemonError struct {
+	Event   string
+	Message string
+}
+
+// consoleMessage is the format of the messages sent both ways through the websocket
+type consoleMessage struct {
+	Event string   `json:"event"`
+	Args  []string `json:"args,omitempty"`
+}
+
+// Console events
+const (
+	eventAuth          = "auth"
+	eventAuthSuccess   = "auth success"
+	eventTokenExpiring = "token expiring"
+	eventTokenExpired  = "token expired"
+	eventJWTError      = "jwt error"
+	eventConsoleOutput = "console output"
+	eventDaemonMessage = "daemon message"
+	eventDaemonError   = "daemon error"
+	eventInstallOutput = "install output"
+	eventStatus        = "status"
+	eventStats         = "stats"
+	eventSendCommand   = "send command"
+	eventSetState      = "set state"
+	eventSendLogs      = "send logs"
+)
+
+//***** Errors *****//
+
+func (e *DaemonError) Error() string {
+	return e.Event + ": " + e.Message
+}
+
+//***** Requests *****//
+
+// GetWebsocketToken fetches the credentials to connect to the websocket of a server. Console takes care of it.
+func (c *ClientCredentials) GetWebsocketToken(id string) (*WebsocketToken, error) {
+	return c.GetWebsocketTokenContext(context.Background(), id)
+}
+
+// GetWebsocketTokenContext is GetWebsocketToken with a context
+func (c *ClientCredentials) GetWebsocketTokenContext(ctx context.Context, id string) (ws *WebsocketToken, err error) {
+	bytes, err := c.query(ctx, "servers/"+id+"/websocket", "GET", nil)
+	if err != nil {
+		return
+	}
+
+	var wrapper struct {
+		Data *WebsocketToken `json:"data"`
+	}
+
+	err = json.Unmarshal(bytes, &wrapper)
+	if err != nil {
+		return
+	}
+
+	return wrapper.Data, nil
+}
+
+// Console connects to the console of a server and authenticates. The token is renewed as needed, until the
+// console is closed.
+func (c *ClientCredentials) Console(id string) (*Console, error) {
+	return c.ConsoleContext(context.Background(), id)
+}
+
+// ConsoleContext is Console with a context. The console is closed when the context is done.
+func (c *ClientCredentials) ConsoleContext(ctx context.Context, id string) (*Console, error) {
+	ws, err := c.GetWebsocketTokenContext(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// The daemon only accepts connections coming from the panel
+	header := http.Header{}
+	header.Set("Origin", c.URL)
+	if c.userAgent != "" {
+		header.Set("User-Agent", c.userAgent)
+	}
+
+	conn, _, err := c.dialer().DialContext(ctx, ws.Socket, header)
+	if err != nil {
+		return nil, err
+	}
+
+	cn := &Console{
+		c:             c,
+		id:            id,
+		conn:          conn,
+		output:        make(chan string, consoleBuffer),
+		status:        make(chan ServerState, consoleBuffer),
+		stats:         make(chan *ConsoleStats, consoleBuffer),
+		installOutput: make(chan string, consoleBuffer),
+		errs:          make(chan error, consoleBuffer),
+		watchers:      make(map[*stateWatch]struct{}),
+		done:          make(chan struct{}),
+	}
+
+	cn.Output = cn.output
+	cn.Status = cn.status
+	cn.Stats = cn.stats
+	cn.InstallOutput = cn.installOutput
+	cn.Errors = cn.errs
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetReadDeadline(deadline)
+	}
+
+	err = cn.authenticate(ws.Token)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	conn.SetReadDeadline(time.Time{})
+
+	cn.ctx, cn.cancel = context.WithCancel(ctx)
+	go cn.run()
+
+	return cn, nil
+}
+
+// dialer builds a websocket dialer sharing the TLS and proxy settings of the HTTP client, when possible
+func (c *ClientCredentials) dialer() *websocket.Dialer {
+	d := &websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: 45 * time.Second,
+	}
+
+	if t, ok := (*Credentials)(c).client().Transport.(*http.Transport); ok {
+		d.Proxy = t.Proxy
+		d.TLSClientConfig = t.TLSClientConfig
+	}
+
+	return d
+}
+
+//***** Console *****//
+
+// SendCommand executes a command on the server. The output is received through the Output channel.
+func (cn *Console) SendCommand(cmd string) error {
+	return cn.send(eventSendCommand, cmd)
+}
+
+// SetPowerState changes the power state of the server. The state can be ON, OFF, RESTART or KILL.
+func (cn *Console) SetPowerState(state PowerSignal) error {
+	return cn.send(eventSetState, string(state))
+}
+
+// RequestLogs asks the daemon for the recent console lines, which are received through the Output channel
+func (cn *Console) RequestLogs() error {
+	return cn.send(eventSendLogs)
+}
+
+// Done returns a channel that's closed once the console is closed
+func (cn *Console) Done() <-chan struct{} {
+	return cn.done
+}
+
+// Err returns the error that closed the console, if any. It returns nil if the console was closed with Close or
+// through its context.
+func (cn *Console) Err() error {
+	select {
+	case <-cn.done:
+		return cn.err
+	default:
+		return nil
+	}
+}
+
+// Close disconnects the console and waits for its channels to be closed
+func (cn *Console) Close() error {
+	select {
+	case <-cn.done:
+		return nil
+	default:
+	}
+
+	cn.mu.Lock()
+	err := cn.conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+	cn.mu.Unlock()
+
+	cn.cancel()
+	<-cn.done
+
+	if err == websocket.ErrCloseSent {
+		return nil
+	}
+
+	return err
+}
+
+// send writes a message to the websocket
+func (cn *Console) send(event string, args ...string) error {
+	cn.mu.Lock()
+	defer cn.mu.Unlock()
+
+	return cn.conn.WriteJSON(consoleMessage{Event: event, Args: args})
+}
+
+// authenticate sends the token and waits for the daemon to accept it
+func (cn *Console) authenticate(token string) error {
+	err := cn.send(eventAuth, token)
+	if err != nil {
+		return err
+	}
+
+	for {
+		var m consoleMessage
+		err = cn.conn.ReadJSON(&m)
+		if err != nil {
+			return err
+		}
+
+		switch m.Event {
+		case eventAuthSuccess:
+			return nil
+		case eventJWTError, eventDaemonError:
+			return &DaemonError{Event: m.Event, Message: firstArg(m)}
+		}
+	}
+}
+
+// run reads the websocket until it's closed, delivering the events to the channels
+func (cn *Console) run() {
+	defer func() {
+		close(cn.output)
+		close(cn.status)
+		close(cn.stats)
+		close(cn.installOutput)
+		close(cn.errs)
+
+		cn.watchMu.Lock()
+		for w := range cn.watchers {
+			close(w.states)
+			close(w.errs)
+		}
+		cn.watchers = nil
+		cn.stopped = true
+		cn.watchMu.Unlock()
+
+		close(cn.done)
+	}()
+
+	// Closing the connection unblocks the pending read
+	go func() {
+		<-cn.ctx.Done()
+		cn.conn.Close()
+	}()
+	defer cn.cancel()
+
+	for {
+		var m consoleMessage
+		err := cn.conn.ReadJSON(&m)
+		if err != nil {
+			if cn.ctx.Err() == nil && !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				cn.err = err
+			}
+
+			return
+		}
+
+		cn.handle(m)
+	}
+}
+
+// handle processes a single event of the websocket
+func (cn *Console) handle(m consoleMessage) {
+	switch m.Event {
+	case eventConsoleOutput, eventDaemonMessage:
+		deliver(cn.output, firstArg(m))
+	case eventInstallOutput:
+		deliver(cn.installOutput, firstArg(m))
+	case eventStatus:
+		state := ServerState(firstArg(m))
+		cn.notify(state)
+
+		select {
+		case cn.status <- state:
+		default:
+		}
+	case eventStats:
+		var stats ConsoleStats
+		if json.Unmarshal([]byte(firstArg(m)), &stats) != nil {
+			return
+		}
+
+		select {
+		case cn.stats <- &stats:
+		default:
+		}
+	case eventDaemonError, eventJWTError:
+		err := &DaemonError{Event: m.Event, Message: firstArg(m)}
+		cn.notifyError(err)
+		cn.report(err)
+	case eventTokenExpiring, eventTokenExpired:
+		cn.report(cn.refresh())
+	}
+}
+
+// refresh fetches a new token and authenticates with it
+func (cn *Console) refresh() error {
+	ws, err := cn.c.GetWebsocketTokenContext(cn.ctx, cn.id)
+	if err != nil {
+		return err
+	}
+
+	return cn.send(eventAuth, ws.Token)
+}
+
+// report sends a non-nil error to the Errors channel
+func (cn *Console) report(err error) {
+	if err == nil {
+		return
+	}
+
+	select {
+	case cn.errs <- err:
+	default:
+	}
+}
+
+// watch subscribes to the status changes and daemon errors received from now on, independently of the Status
+// and Errors channels. It also returns the last state received. The channels are closed along with the console.
+func (cn *Console) watch() (*stateWatch, ServerState) {
+	w := &stateWatch{
+		states: make(chan ServerState, consoleBuffer),
+		errs:   make(chan error, consoleBuffer),
+	}
+
+	cn.watchMu.Lock()
+	defer cn.watchMu.Unlock()
+
+	if cn.stopped {
+		close(w.states)
+		close(w.errs)
+	} else {
+		cn.watchers[w] = struct{}{}
+	}
+
+	return w, cn.state
+}
+
+// unwatch cancels a subscription made with watch
+func (cn *Console) unwatch(w *stateWatch) {
+	cn.watchMu.Lock()
+	defer cn.watchMu.Unlock()
+
+	delete(cn.watchers, w)
+}
+
+// notify records the state and sends it to the watchers, dropping it for those that are full
+func (cn *Console) notify(state ServerState) {
+	cn.watchMu.Lock()
+	defer cn.watchMu.Unlock()
+
+	cn.state = state
+	for w := range cn.watchers {
+		select {
+		case w.states <- state:
+		default:
+		}
+	}
+}
+
+// notifyError sends a daemon error to the watchers, dropping it for those that are full
+func (cn *Console) notifyError(err error) {
+	cn.watchMu.Lock()
+	defer cn.watchMu.Unlock()
+
+	for w := range cn.watchers {
+		select {
+		case w.errs <- err:
+		default:
+		}
+	}
+}
+
+//***** Helpers *****//
+
+// deliver sends the value to the channel, dropping it if the channel is full
+func deliver(ch chan string, v string) {
+	select {
+	case ch <- v:
+	default:
+	}
+}
+
+// firstArg returns the first argument of a message, or an empty string if there's none
+func firstArg(m consoleMessage) string {
+	if len(m.Args) == 0 {
+		return ""
+	}
+
+	return m.Args[0]
+}
@@ -0,0 +1,201 @@
+package fossil
+
+import (
+	"context"
+	"github.com/gorilla/websocket"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+//***** Testing *****//
+
+// wingsStub serves a websocket that behaves like the daemon's, handing each connection to the handler once
+// authenticated
+func wingsStub(t *testing.T, handler func(conn *websocket.Conn)) *httptest.Server {
+	upgrader := websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Origin") != "https://example.com" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("Error: %s", err.Error())
+			return
+		}
+		defer conn.Close()
+
+		var m consoleMessage
+		err = conn.ReadJSON(&m)
+		if err != nil {
+			return
+		}
+
+		if m.Event != eventAuth || len(m.Args) != 1 || m.Args[0] != "token-1" {
+			_ = conn.WriteJSON(consoleMessage{Event: eventJWTError, Args: []string{"invalid token"}})
+			return
+		}
+
+		_ = conn.WriteJSON(consoleMessage{Event: eventAuthSuccess})
+		handler(conn)
+	}))
+}
+
+// stubWebsocketToken makes the panel hand out the given tokens for the stub daemon, in order
+func stubWebsocketToken(t *testing.T, srv *httptest.Server, tokens ...string) {
+	var i int
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/websocket"
+		if expectURL != url {
+			t.Errorf("Request url does not match expected: %s", url)
+		}
+
+		token := tokens[i]
+		if i < len(tokens)-1 {
+			i++
+		}
+
+		socket := "ws" + strings.TrimPrefix(srv.URL, "http") + "/api/servers/1a7ce997/ws"
+		return []byte(`{"data":{"token":"` + token + `","socket":"` + socket + `"}}`), nil
+	}
+}
+
+func TestClientCredentials_Console(t *testing.T) {
+	received := make(chan consoleMessage, 10)
+	srv := wingsStub(t, func(conn *websocket.Conn) {
+		_ = conn.WriteJSON(consoleMessage{Event: eventStatus, Args: []string{"running"}})
+		_ = conn.WriteJSON(consoleMessage{Event: eventStats, Args: []string{
+			`{"memory_bytes":524288000,"memory_limit_bytes":1073741824,"cpu_absolute":12.5,` +
+				`"network":{"rx_bytes":1024,"tx_bytes":2048},"state":"running","disk_bytes":1048576,"uptime":60000}`,
+		}})
+		_ = conn.WriteJSON(consoleMessage{Event: eventDaemonError, Args: []string{"disk space exceeded"}})
+		_ = conn.WriteJSON(consoleMessage{Event: eventTokenExpiring})
+
+		for {
+			var m consoleMessage
+			err := conn.ReadJSON(&m)
+			if err != nil {
+				return
+			}
+
+			received <- m
+			if m.Event == eventSendCommand {
+				_ = conn.WriteJSON(consoleMessage{Event: eventConsoleOutput, Args: []string{"> " + m.Args[0]}})
+			}
+		}
+	})
+	defer srv.Close()
+
+	stubWebsocketToken(t, srv, "token-1", "token-2")
+
+	c := NewClient("https://example.com", "")
+
+	cn, err := c.Console("1a7ce997")
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+	defer cn.Close()
+
+	if status := <-cn.Status; status != "running" {
+		t.Errorf("Unexpected status: %s", status)
+	}
+
+	stats := <-cn.Stats
+	if stats.MemoryBytes != 524288000 || stats.CPUAbsolute != 12.5 || stats.Network.TxBytes != 2048 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	err = <-cn.Errors
+	if de, ok := err.(*DaemonError); !ok || de.Message != "disk space exceeded" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	// The token must be renewed once the daemon warns about its expiration
+	if m := <-received; m.Event != eventAuth || m.Args[0] != "token-2" {
+		t.Errorf("Unexpected token refresh: %+v", m)
+	}
+
+	err = cn.SendCommand("say Hello!")
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	if line := <-cn.Output; line != "> say Hello!" {
+		t.Errorf("Unexpected output: %s", line)
+	}
+
+	err = cn.SetPowerState(RESTART)
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	<-received // The command
+	if m := <-received; m.Event != eventSetState || m.Args[0] != string(RESTART) {
+		t.Errorf("Unexpected power message: %+v", m)
+	}
+
+	err = cn.Close()
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	if _, ok := <-cn.Output; ok {
+		t.Error("The channels were not closed")
+	}
+
+	if cn.Err() != nil {
+		t.Errorf("Unexpected error after close: %s", cn.Err())
+	}
+}
+
+func TestClientCredentials_Console_InvalidToken(t *testing.T) {
+	srv := wingsStub(t, func(conn *websocket.Conn) {})
+	defer srv.Close()
+
+	stubWebsocketToken(t, srv, "expired")
+
+	c := NewClient("https://example.com", "")
+
+	_, err := c.Console("1a7ce997")
+	if de, ok := err.(*DaemonError); !ok || de.Event != eventJWTError {
+		t.Errorf("Expected a jwt error, got: %v", err)
+	}
+}
+
+func TestClientCredentials_Console_Disconnect(t *testing.T) {
+	srv := wingsStub(t, func(conn *websocket.Conn) {
+		_ = conn.WriteJSON(consoleMessage{Event: eventInstallOutput, Args: []string{"Installing..."}})
+	})
+	defer srv.Close()
+
+	stubWebsocketToken(t, srv, "token-1")
+
+	c := NewClient("https://example.com", "")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cn, err := c.ConsoleContext(ctx, "1a7ce997")
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	if line := <-cn.InstallOutput; line != "Installing..." {
+		t.Errorf("Unexpected install output: %s", line)
+	}
+
+	select {
+	case <-cn.Done():
+	case <-ctx.Done():
+		t.Fatal("The console was not closed after the daemon disconnected")
+	}
+
+	if cn.Err() == nil {
+		t.Error("Expected the disconnection error")
+	}
+}
@@ -1,6 +1,7 @@
 package fossil
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -20,11 +21,80 @@ type Database struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// ClientDatabase is a server database as seen by the server's owner
+type ClientDatabase struct {
+	ID             string       `json:"id"`
+	Host           DatabaseHost `json:"host"`
+	Name           string       `json:"name"`
+	Username       string       `json:"username"`
+	Password       string       `json:"-"` // Only set when requested, see ListDatabases
+	Remote         string       `json:"connections_from"`
+	MaxConnections int          `json:"max_connections"`
+}
+
+// DatabaseHost is the address where a database can be reached
+type DatabaseHost struct {
+	Address string `json:"address"`
+	Port    int    `json:"port"`
+}
+
+// jsonClientDatabase contains the database info and its password.
+// It's used as the target struct in the unmarshalling of API responses.
+type jsonClientDatabase struct {
+	ClientDatabase
+	Relationships struct {
+		Password struct {
+			Attributes struct {
+				Password string `json:"password"`
+			} `json:"attributes"`
+		} `json:"password"`
+	} `json:"relationships"`
+}
+
+//***** Converters *****//
+
+// asClientDatabase parses a jsonClientDatabase into a *ClientDatabase
+func (db *jsonClientDatabase) asClientDatabase() *ClientDatabase {
+	cdb := db.ClientDatabase
+	cdb.Password = db.Relationships.Password.Attributes.Password
+
+	return &cdb
+}
+
+// decodeClientDatabase parses a single client database response
+func decodeClientDatabase(bytes []byte) (*ClientDatabase, error) {
+	var wrapper struct {
+		Database *jsonClientDatabase `json:"attributes"`
+	}
+
+	err := json.Unmarshal(bytes, &wrapper)
+	if err != nil {
+		return nil, err
+	}
+
+	if wrapper.Database == nil {
+		return nil, nil
+	}
+
+	return wrapper.Database.asClientDatabase(), nil
+}
+
+//***** String *****//
+
+func (h DatabaseHost) String() string {
+	return fmt.Sprintf("%s:%d", h.Address, h.Port)
+}
+
 //***** Requests *****//
 
 // GetDatabases fetches all the associated databases for a server
-func (c *ApplicationCredentials) GetDatabases(sid int) (dbs []*Database, err error) {
-	bytes, err := c.query(fmt.Sprintf("servers/%d/databases", sid), "GET", nil)
+func (c *ApplicationCredentials) GetDatabases(sid int) ([]*Database, error) {
+	return c.GetDatabasesContext(context.Background(), sid)
+}
+
+// GetDatabasesContext is GetDatabases with a context
+func (c *ApplicationCredentials) GetDatabasesContext(ctx context.Context, sid int) (dbs []*Database, err error) {
+	bytes, err := c.query(ctx, fmt.Sprintf("servers/%d/databases", sid), "GET", nil)
 	if err != nil {
 		return
 	}
@@ -48,8 +118,13 @@ func (c *ApplicationCredentials) GetDatabases(sid int) (dbs []*Database, err err
 }
 
 // GetDatabase fetches, if present, the database matching the id in the server's databases
-func (c *ApplicationCredentials) GetDatabase(sid int, dbid int) (db *Database, err error) {
-	bytes, err := c.query(fmt.Sprintf("servers/%d/databases/%d", sid, dbid), "GET", nil)
+func (c *ApplicationCredentials) GetDatabase(sid int, dbid int) (*Database, error) {
+	return c.GetDatabaseContext(context.Background(), sid, dbid)
+}
+
+// GetDatabaseContext is GetDatabase with a context
+func (c *ApplicationCredentials) GetDatabaseContext(ctx context.Context, sid int, dbid int) (db *Database, err error) {
+	bytes, err := c.query(ctx, fmt.Sprintf("servers/%d/databases/%d", sid, dbid), "GET", nil)
 	if err != nil {
 		return
 	}
@@ -67,7 +142,25 @@ func (c *ApplicationCredentials) GetDatabase(sid int, dbid int) (db *Database, e
 }
 
 // CreateDatabase creates a new database based on the provided information
-func (c *ApplicationCredentials) CreateDatabase(sid int, db *Database) (err error) {
+func (c *ApplicationCredentials) CreateDatabase(sid int, db *Database) error {
+	return c.CreateDatabaseContext(context.Background(), sid, db)
+}
+
+// CreateDatabaseContext is CreateDatabase with a context
+func (c *ApplicationCredentials) CreateDatabaseContext(ctx context.Context, sid int, db *Database) (err error) {
+	_, err = c.CreateDatabaseReturningContext(ctx, sid, db)
+	return
+}
+
+// CreateDatabaseReturning is CreateDatabase, but returns the database as created by the panel. The passed
+// database is left untouched.
+func (c *ApplicationCredentials) CreateDatabaseReturning(sid int, db *Database) (*Database, error) {
+	return c.CreateDatabaseReturningContext(context.Background(), sid, db)
+}
+
+// CreateDatabaseReturningContext is CreateDatabaseReturning with a context
+func (c *ApplicationCredentials) CreateDatabaseReturningContext(ctx context.Context, sid int,
+	db *Database) (created *Database, err error) {
 	type databaseCreate struct {
 		Database string `json:"database"`
 		Remote   string `json:"remote"`
@@ -80,22 +173,139 @@ func (c *ApplicationCredentials) CreateDatabase(sid int, db *Database) (err erro
 		Host:     db.Host,
 	}
 
-	bytes, err := json.Marshal(dbStruct)
+	rq, err := json.Marshal(dbStruct)
 	if err != nil {
-		return err
+		return
 	}
-	_, err = c.query(fmt.Sprintf("servers/%d/databases", sid), "POST", bytes)
-	return
+
+	bytes, err := c.query(ctx, fmt.Sprintf("servers/%d/databases", sid), "POST", rq)
+	if err != nil {
+		return
+	}
+
+	var wrapper struct {
+		Database *Database `json:"attributes"`
+	}
+
+	err = json.Unmarshal(bytes, &wrapper)
+	if err != nil {
+		return
+	}
+
+	return wrapper.Database, nil
 }
 
 // ResetDatabasePassword resets the password for the specified database of the specified server
-func (c *ApplicationCredentials) ResetDatabasePassword(sid int, dbid int) (err error) {
-	_, err = c.query(fmt.Sprintf("servers/%d/databases/%d/reset-password", sid, dbid), "POST", nil)
+func (c *ApplicationCredentials) ResetDatabasePassword(sid int, dbid int) error {
+	return c.ResetDatabasePasswordContext(context.Background(), sid, dbid)
+}
+
+// ResetDatabasePasswordContext is ResetDatabasePassword with a context
+func (c *ApplicationCredentials) ResetDatabasePasswordContext(ctx context.Context, sid int, dbid int) (err error) {
+	_, err = c.query(ctx, fmt.Sprintf("servers/%d/databases/%d/reset-password", sid, dbid), "POST", nil)
 	return
 }
 
 // DeleteDatabase marks the specified database in the specified server for deletion
-func (c *ApplicationCredentials) DeleteDatabase(sid int, dbid int) (err error) {
-	_, err = c.query(fmt.Sprintf("servers/%d/databases/%d", sid, dbid), "DELETE", nil)
+func (c *ApplicationCredentials) DeleteDatabase(sid int, dbid int) error {
+	return c.DeleteDatabaseContext(context.Background(), sid, dbid)
+}
+
+// DeleteDatabaseContext is DeleteDatabase with a context
+func (c *ApplicationCredentials) DeleteDatabaseContext(ctx context.Context, sid int, dbid int) (err error) {
+	_, err = c.query(ctx, fmt.Sprintf("servers/%d/databases/%d", sid, dbid), "DELETE", nil)
+	return
+}
+
+// ListDatabases fetches the databases of a server. The passwords are only included if requested, which requires
+// the database.view_password permission.
+func (c *ClientCredentials) ListDatabases(id string, includePassword bool) ([]*ClientDatabase, error) {
+	return c.ListDatabasesContext(context.Background(), id, includePassword)
+}
+
+// ListDatabasesContext is ListDatabases with a context
+func (c *ClientCredentials) ListDatabasesContext(ctx context.Context, id string,
+	includePassword bool) (dbs []*ClientDatabase, err error) {
+	endpoint := "servers/" + id + "/databases"
+	if includePassword {
+		endpoint += "?include=password"
+	}
+
+	bytes, err := c.query(ctx, endpoint, "GET", nil)
+	if err != nil {
+		return
+	}
+
+	var wrapper struct {
+		Data []struct {
+			Database *jsonClientDatabase `json:"attributes"`
+		} `json:"data"`
+	}
+
+	err = json.Unmarshal(bytes, &wrapper)
+	if err != nil {
+		return
+	}
+
+	for _, db := range wrapper.Data {
+		if db.Database != nil {
+			dbs = append(dbs, db.Database.asClientDatabase())
+		}
+	}
+
+	return dbs, nil
+}
+
+// CreateDatabase makes a new database for the server, reachable from the hosts matching the remote pattern
+// ("%" allows any host). The created database is returned along with its password.
+func (c *ClientCredentials) CreateDatabase(id, name, remote string) (*ClientDatabase, error) {
+	return c.CreateDatabaseContext(context.Background(), id, name, remote)
+}
+
+// CreateDatabaseContext is CreateDatabase with a context
+func (c *ClientCredentials) CreateDatabaseContext(ctx context.Context, id, name,
+	remote string) (db *ClientDatabase, err error) {
+	type wrapper struct {
+		Database string `json:"database"`
+		Remote   string `json:"remote"`
+	}
+
+	rq, err := json.Marshal(wrapper{Database: name, Remote: remote})
+	if err != nil {
+		return
+	}
+
+	bytes, err := c.query(ctx, "servers/"+id+"/databases", "POST", rq)
+	if err != nil {
+		return
+	}
+
+	return decodeClientDatabase(bytes)
+}
+
+// RotateDatabasePassword generates a new password for the database, and returns the database with it
+func (c *ClientCredentials) RotateDatabasePassword(id, dbID string) (*ClientDatabase, error) {
+	return c.RotateDatabasePasswordContext(context.Background(), id, dbID)
+}
+
+// RotateDatabasePasswordContext is RotateDatabasePassword with a context
+func (c *ClientCredentials) RotateDatabasePasswordContext(ctx context.Context, id,
+	dbID string) (*ClientDatabase, error) {
+	bytes, err := c.query(ctx, "servers/"+id+"/databases/"+dbID+"/rotate-password", "POST", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeClientDatabase(bytes)
+}
+
+// DeleteDatabase deletes a database of the server
+func (c *ClientCredentials) DeleteDatabase(id, dbID string) error {
+	return c.DeleteDatabaseContext(context.Background(), id, dbID)
+}
+
+// DeleteDatabaseContext is DeleteDatabase with a context
+func (c *ClientCredentials) DeleteDatabaseContext(ctx context.Context, id, dbID string) (err error) {
+	_, err = c.query(ctx, "servers/"+id+"/databases/"+dbID, "DELETE", nil)
 	return
 }
@@ -1,13 +1,14 @@
 package fossil
 
 import (
+	"context"
 	"github.com/google/go-cmp/cmp"
 	"testing"
 	"time"
 )
 
 func TestApplicationCredentials_GetDatabases(t *testing.T) {
-	query = func(url, token, method string, data []byte) ([]byte, error) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
 		res := `{
 		"object": "list",
 	  	"data": [
@@ -85,7 +86,7 @@ func TestApplicationCredentials_GetDatabases(t *testing.T) {
 }
 
 func TestApplicationCredentials_GetDatabase(t *testing.T) {
-	query = func(url, token, method string, data []byte) ([]byte, error) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
 		expectURL := "https://example.com/api/application/servers/1/databases/1"
 		if expectURL != url {
 			t.Errorf("Request url does not match expected: %s", url)
@@ -134,7 +135,7 @@ func TestApplicationCredentials_GetDatabase(t *testing.T) {
 	}
 }
 func TestApplicationCredentials_CreateDatabase(t *testing.T) {
-	query = func(url, token, method string, data []byte) ([]byte, error) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
 		expectURL := "https://example.com/api/application/servers/1/databases"
 		if expectURL != url {
 			t.Errorf("Request url does not match expected: %s", url)
@@ -145,7 +146,21 @@ func TestApplicationCredentials_CreateDatabase(t *testing.T) {
 			t.Errorf("Request body does not match expected: %s", data)
 		}
 
-		return nil, nil
+		res := `{
+		  "object": "server_database",
+		  "attributes": {
+			"id": 8,
+			"server": 1,
+			"host": 2,
+			"database": "s1_mydb",
+			"username": "u1_WZ8nKa1Tgt",
+			"remote": "%",
+			"created_at": "2019-10-06T15:30:02+02:00",
+			"updated_at": "2019-10-06T15:30:02+02:00"
+		  }
+		}`
+
+		return []byte(res), nil
 	}
 
 	a := NewApplication("https://example.com", "")
@@ -156,14 +171,27 @@ func TestApplicationCredentials_CreateDatabase(t *testing.T) {
 		Remote:   "%",
 	}
 
-	err := a.CreateDatabase(1, db)
+	got, err := a.CreateDatabaseReturning(1, db)
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	if got.ID != 8 || got.Username != "u1_WZ8nKa1Tgt" {
+		t.Errorf("Unexpected response: %+v", got)
+	}
+
+	if db.ID != 0 || db.Username != "" {
+		t.Errorf("The passed database was modified: %+v", db)
+	}
+
+	err = a.CreateDatabase(1, db)
 	if err != nil {
 		t.Errorf("Error: %s", err.Error())
 	}
 }
 
 func TestApplicationCredentials_ResetDatabasePassword(t *testing.T) {
-	query = func(url, token, method string, data []byte) ([]byte, error) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
 		expectURL := "https://example.com/api/application/servers/1/databases/1/reset-password"
 		if expectURL != url {
 			t.Errorf("Request url does not match expected: %s", url)
@@ -181,7 +209,7 @@ func TestApplicationCredentials_ResetDatabasePassword(t *testing.T) {
 }
 
 func TestApplicationCredentials_DeleteDatabase(t *testing.T) {
-	query = func(url, token, method string, data []byte) ([]byte, error) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
 		expectURL := "https://example.com/api/application/servers/1/databases/1"
 		if expectURL != url {
 			t.Errorf("Request url does not match expected: %s", url)
@@ -197,3 +225,135 @@ func TestApplicationCredentials_DeleteDatabase(t *testing.T) {
 		t.Errorf("Error: %s", err.Error())
 	}
 }
+
+func TestClientCredentials_ListDatabases(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/databases?include=password"
+		if expectURL != url {
+			t.Errorf("Request url does not match expected: %s", url)
+		}
+
+		res := `{
+		  "object": "list",
+		  "data": [
+			{
+			  "object": "server_database",
+			  "attributes": {
+				"id": "bEY4yAD5",
+				"host": {
+				  "address": "127.0.0.1",
+				  "port": 3306
+				},
+				"name": "s5_perms",
+				"username": "u5_QsIAp1jhvS",
+				"connections_from": "%",
+				"max_connections": 0,
+				"relationships": {
+				  "password": {
+					"object": "database_password",
+					"attributes": {
+					  "password": "BjUgJo4q7xJmtvgk"
+					}
+				  }
+				}
+			  }
+			},
+			{}
+		  ]
+		}`
+
+		// Entries without attributes are skipped
+		return []byte(res), nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	got, err := c.ListDatabases("1a7ce997", true)
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	expect := []*ClientDatabase{
+		{
+			ID:       "bEY4yAD5",
+			Host:     DatabaseHost{Address: "127.0.0.1", Port: 3306},
+			Name:     "s5_perms",
+			Username: "u5_QsIAp1jhvS",
+			Password: "BjUgJo4q7xJmtvgk",
+			Remote:   "%",
+		},
+	}
+
+	if !cmp.Equal(got, expect) {
+		t.Errorf("Unexpected response: %s", cmp.Diff(got, expect))
+	}
+}
+
+func TestClientCredentials_CreateDatabase(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/databases"
+		if expectURL != url || method != "POST" {
+			t.Errorf("Request url does not match expected: %s %s", method, url)
+		}
+
+		expectBody := `{"database":"stats","remote":"10.0.0.%"}`
+		if expectBody != string(data) {
+			t.Errorf("Request body does not match expected: %s", data)
+		}
+
+		return []byte(`{"object":"server_database","attributes":{"id":"y9YVDBN7","name":"s5_stats",
+			"relationships":{"password":{"attributes":{"password":"Xg4ux2tF"}}}}}`), nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	got, err := c.CreateDatabase("1a7ce997", "stats", "10.0.0.%")
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	if got.ID != "y9YVDBN7" || got.Password != "Xg4ux2tF" {
+		t.Errorf("Unexpected response: %+v", got)
+	}
+}
+
+func TestClientCredentials_RotateDatabasePassword(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/databases/y9YVDBN7/rotate-password"
+		if expectURL != url || method != "POST" {
+			t.Errorf("Request url does not match expected: %s %s", method, url)
+		}
+
+		return []byte(`{"object":"server_database","attributes":{"id":"y9YVDBN7",
+			"relationships":{"password":{"attributes":{"password":"Pm3e7Lr1"}}}}}`), nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	got, err := c.RotateDatabasePassword("1a7ce997", "y9YVDBN7")
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	if got.Password != "Pm3e7Lr1" {
+		t.Errorf("Unexpected response: %+v", got)
+	}
+}
+
+func TestClientCredentials_DeleteDatabase(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/databases/y9YVDBN7"
+		if expectURL != url || method != "DELETE" {
+			t.Errorf("Request url does not match expected: %s %s", method, url)
+		}
+
+		return nil, nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	err := c.DeleteDatabase("1a7ce997", "y9YVDBN7")
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+}
@@ -0,0 +1,153 @@
+package fossil
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+//***** Structures *****//
+
+// APIError is returned when the panel responds with a non-success status. It can be retrieved from any returned
+// error using errors.As, or inspected with helpers like IsNotFound.
+type APIError struct {
+	StatusCode int
+	Status     string
+	Errors     []*ErrorDetail
+	Body       []byte // Raw response body, kept for when the error details can't be decoded
+}
+
+// ErrorDetail contains a single error entry of a Pterodactyl error response
+type ErrorDetail struct {
+	Code   string                 `json:"code"`
+	Status string                 `json:"status"` // For some reason the status is given as a string
+	Detail string                 `json:"detail"`
+	Source *ErrorSource           `json:"source,omitempty"`
+	Meta   map[string]interface{} `json:"meta,omitempty"`
+}
+
+// ErrorSource points to the part of the request that caused an error
+type ErrorSource struct {
+	Pointer string `json:"pointer,omitempty"`
+	Field   string `json:"field,omitempty"`
+}
+
+// jsonRequestErrors allows the JSON provided to be decoded
+type jsonRequestErrors struct {
+	Errors []*ErrorDetail `json:"errors"`
+}
+
+//***** Errors *****//
+
+// newAPIError builds an APIError out of a non-success response and its body
+func newAPIError(rp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: rp.StatusCode,
+		Status:     rp.Status,
+		Body:       body,
+	}
+
+	details, err := parseError(body)
+	if err == nil {
+		apiErr.Errors = details
+	}
+
+	return apiErr
+}
+
+// parseError takes a Pterodactyl-formatted error and parses all of its entries
+func parseError(bytes []byte) ([]*ErrorDetail, error) {
+	var rqErrors jsonRequestErrors
+	err := json.Unmarshal(bytes, &rqErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rqErrors.Errors) < 1 {
+		return nil, errors.New("no error details given")
+	}
+
+	return rqErrors.Errors, nil
+}
+
+func (e *APIError) Error() string {
+	if len(e.Errors) < 1 {
+		return "remote server responded with status " + e.Status
+	}
+
+	d := e.Errors[0]
+	msg := fmt.Sprintf("remote server responded with status %s (%s): %s", e.Status, d.Code, d.Detail)
+	if len(e.Errors) > 1 {
+		msg += fmt.Sprintf(" (and %d more errors)", len(e.Errors)-1)
+	}
+
+	return msg
+}
+
+// Field returns the request field that caused the error, if given
+func (d *ErrorDetail) Field() string {
+	if d.Source != nil {
+		if d.Source.Field != "" {
+			return d.Source.Field
+		}
+
+		return d.Source.Pointer
+	}
+
+	// Validation errors carry the field in the metadata
+	if f, ok := d.Meta["source_field"].(string); ok {
+		return f
+	}
+
+	return ""
+}
+
+//***** Helpers *****//
+
+// IsNotFound reports whether the error was caused by a missing resource
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsConflict reports whether the error was caused by a conflict with the current state of a resource
+func IsConflict(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+// IsUnauthorized reports whether the error was caused by a missing or invalid token
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+// IsForbidden reports whether the token lacks the permissions for the request
+func IsForbidden(err error) bool {
+	return hasStatus(err, http.StatusForbidden)
+}
+
+// IsValidationError reports whether the request data was rejected by the panel validation
+func IsValidationError(err error) bool {
+	return hasStatus(err, http.StatusUnprocessableEntity)
+}
+
+// hasStatus checks if err is an APIError with the given status code
+func hasStatus(err error, status int) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	if apiErr.StatusCode == status {
+		return true
+	}
+
+	// Some daemons report the real status only inside the error entries
+	for _, d := range apiErr.Errors {
+		if d.Status == strconv.Itoa(status) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,94 @@
+package fossil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+//***** Testing *****//
+
+func TestQueryURL_APIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte(`{
+		  "errors": [
+			{
+			  "code": "ValidationException",
+			  "status": "422",
+			  "detail": "The name field is required.",
+			  "meta": {
+				"source_field": "name",
+				"rule": "required"
+			  }
+			},
+			{
+			  "code": "ValidationException",
+			  "status": "422",
+			  "detail": "The user field is required.",
+			  "source": {
+				"field": "user"
+			  }
+			}
+		  ]
+		}`))
+	}))
+	defer srv.Close()
+
+	_, err := queryURL(context.Background(), &Credentials{}, srv.URL, "POST", nil)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected an APIError, got: %v", err)
+	}
+
+	if apiErr.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("Unexpected status code: %d", apiErr.StatusCode)
+	}
+
+	if len(apiErr.Errors) != 2 {
+		t.Fatalf("Expected 2 error entries, got %d", len(apiErr.Errors))
+	}
+
+	if apiErr.Errors[0].Field() != "name" || apiErr.Errors[1].Field() != "user" {
+		t.Errorf("Unexpected error fields: %s, %s", apiErr.Errors[0].Field(), apiErr.Errors[1].Field())
+	}
+
+	if !IsValidationError(err) {
+		t.Error("Expected a validation error")
+	}
+}
+
+func TestQueryURL_APIErrorNoDetails(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	_, err := queryURL(context.Background(), &Credentials{}, srv.URL, "GET", nil)
+	if !IsNotFound(err) {
+		t.Errorf("Expected a not found error, got: %v", err)
+	}
+
+	if err.Error() != "remote server responded with status 404 Not Found" {
+		t.Errorf("Unexpected error message: %s", err.Error())
+	}
+}
+
+func TestIsNotFound(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", &APIError{StatusCode: http.StatusNotFound})
+	if !IsNotFound(err) {
+		t.Error("Wrapped APIError was not detected")
+	}
+
+	if IsConflict(err) || IsUnauthorized(err) || IsForbidden(err) {
+		t.Error("Unexpected status match")
+	}
+
+	if IsNotFound(errors.New("not found")) {
+		t.Error("Plain error should not match")
+	}
+}
@@ -0,0 +1,324 @@
+package fossil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+)
+
+//***** Structures *****//
+
+// FileInfo describes a file or directory inside a server
+type FileInfo struct {
+	Name       string    `json:"name"`
+	Mode       string    `json:"mode"`      // Symbolic notation, like -rw-r--r--
+	ModeBits   string    `json:"mode_bits"` // Octal notation, like 644
+	Size       int64     `json:"size"`
+	IsFile     bool      `json:"is_file"`
+	IsSymlink  bool      `json:"is_symlink"`
+	MimeType   string    `json:"mimetype"`
+	CreatedAt  time.Time `json:"created_at"`
+	ModifiedAt time.Time `json:"modified_at"`
+}
+
+// FileRename is a single rename or move of a file, relative to the root passed to RenameFiles
+type FileRename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// FileChmod is a single permission change of a file, relative to the root passed to ChmodFiles
+type FileChmod struct {
+	File string
+	Mode os.FileMode
+}
+
+// PullOptions changes how the daemon fetches a remote file
+type PullOptions struct {
+	UseHeader  bool // Name the file after the Content-Disposition header of the remote response
+	Foreground bool // Wait for the file to be fully downloaded before responding
+}
+
+// jsonFileList contains a directory listing.
+// It's used as the target struct in the unmarshalling of API responses.
+type jsonFileList struct {
+	Data []struct {
+		File *FileInfo `json:"attributes"`
+	} `json:"data"`
+}
+
+//***** Converters *****//
+
+// MarshalJSON formats the mode in the octal notation expected by the API
+func (f FileChmod) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		File string `json:"file"`
+		Mode string `json:"mode"`
+	}{
+		File: f.File,
+		Mode: fmt.Sprintf("%o", f.Mode.Perm()),
+	})
+}
+
+//***** Helpers *****//
+
+// IsDir reports whether the file is a directory
+func (f *FileInfo) IsDir() bool {
+	return !f.IsFile && !f.IsSymlink
+}
+
+//***** String *****//
+
+func (f *FileInfo) String() string {
+	return f.Name
+}
+
+//***** Requests *****//
+
+// ListFiles fetches the contents of a directory of the server. The root directory is "/".
+func (c *ClientCredentials) ListFiles(id, directory string) ([]*FileInfo, error) {
+	return c.ListFilesContext(context.Background(), id, directory)
+}
+
+// ListFilesContext is ListFiles with a context
+func (c *ClientCredentials) ListFilesContext(ctx context.Context, id, directory string) (files []*FileInfo, err error) {
+	bytes, err := c.query(ctx, "servers/"+id+"/files/list?directory="+url.QueryEscape(directory), "GET", nil)
+	if err != nil {
+		return
+	}
+
+	var list jsonFileList
+	err = json.Unmarshal(bytes, &list)
+	if err != nil {
+		return
+	}
+
+	for _, d := range list.Data {
+		files = append(files, d.File)
+	}
+
+	return files, nil
+}
+
+// ReadFile fetches the contents of a file of the server. Large files should be downloaded instead.
+func (c *ClientCredentials) ReadFile(id, file string) ([]byte, error) {
+	return c.ReadFileContext(context.Background(), id, file)
+}
+
+// ReadFileContext is ReadFile with a context
+func (c *ClientCredentials) ReadFileContext(ctx context.Context, id, file string) ([]byte, error) {
+	return c.query(ctx, "servers/"+id+"/files/contents?file="+url.QueryEscape(file), "GET", nil)
+}
+
+// WriteFile replaces the contents of a file of the server, creating it if needed
+func (c *ClientCredentials) WriteFile(id, file string, content []byte) error {
+	return c.WriteFileContext(context.Background(), id, file, content)
+}
+
+// WriteFileContext is WriteFile with a context
+func (c *ClientCredentials) WriteFileContext(ctx context.Context, id, file string, content []byte) (err error) {
+	_, err = c.query(ctx, "servers/"+id+"/files/write?file="+url.QueryEscape(file), "POST", content)
+	return
+}
+
+// RenameFiles renames or moves files of the server. The paths of the renames are relative to root.
+func (c *ClientCredentials) RenameFiles(id, root string, renames ...FileRename) error {
+	return c.RenameFilesContext(context.Background(), id, root, renames...)
+}
+
+// RenameFilesContext is RenameFiles with a context
+func (c *ClientCredentials) RenameFilesContext(ctx context.Context, id, root string, renames ...FileRename) (err error) {
+	type wrapper struct {
+		Root  string       `json:"root"`
+		Files []FileRename `json:"files"`
+	}
+
+	bytes, err := json.Marshal(wrapper{Root: root, Files: renames})
+	if err != nil {
+		return
+	}
+
+	_, err = c.query(ctx, "servers/"+id+"/files/rename", "PUT", bytes)
+	return
+}
+
+// CopyFile makes a copy of a file in the same directory. The panel picks the name of the copy.
+func (c *ClientCredentials) CopyFile(id, file string) error {
+	return c.CopyFileContext(context.Background(), id, file)
+}
+
+// CopyFileContext is CopyFile with a context
+func (c *ClientCredentials) CopyFileContext(ctx context.Context, id, file string) (err error) {
+	type wrapper struct {
+		Location string `json:"location"`
+	}
+
+	bytes, err := json.Marshal(wrapper{Location: file})
+	if err != nil {
+		return
+	}
+
+	_, err = c.query(ctx, "servers/"+id+"/files/copy", "POST", bytes)
+	return
+}
+
+// DeleteFiles deletes files and directories of the server. The file paths are relative to root.
+func (c *ClientCredentials) DeleteFiles(id, root string, files ...string) error {
+	return c.DeleteFilesContext(context.Background(), id, root, files...)
+}
+
+// DeleteFilesContext is DeleteFiles with a context
+func (c *ClientCredentials) DeleteFilesContext(ctx context.Context, id, root string, files ...string) (err error) {
+	type wrapper struct {
+		Root  string   `json:"root"`
+		Files []string `json:"files"`
+	}
+
+	bytes, err := json.Marshal(wrapper{Root: root, Files: files})
+	if err != nil {
+		return
+	}
+
+	_, err = c.query(ctx, "servers/"+id+"/files/delete", "POST", bytes)
+	return
+}
+
+// CreateFolder creates a new directory called name inside root
+func (c *ClientCredentials) CreateFolder(id, root, name string) error {
+	return c.CreateFolderContext(context.Background(), id, root, name)
+}
+
+// CreateFolderContext is CreateFolder with a context
+func (c *ClientCredentials) CreateFolderContext(ctx context.Context, id, root, name string) (err error) {
+	type wrapper struct {
+		Root string `json:"root"`
+		Name string `json:"name"`
+	}
+
+	bytes, err := json.Marshal(wrapper{Root: root, Name: name})
+	if err != nil {
+		return
+	}
+
+	_, err = c.query(ctx, "servers/"+id+"/files/create-folder", "POST", bytes)
+	return
+}
+
+// CompressFiles creates an archive inside root with the given files, and returns the created archive
+func (c *ClientCredentials) CompressFiles(id, root string, files ...string) (*FileInfo, error) {
+	return c.CompressFilesContext(context.Background(), id, root, files...)
+}
+
+// CompressFilesContext is CompressFiles with a context
+func (c *ClientCredentials) CompressFilesContext(ctx context.Context, id, root string,
+	files ...string) (archive *FileInfo, err error) {
+	type wrapper struct {
+		Root  string   `json:"root"`
+		Files []string `json:"files"`
+	}
+
+	rq, err := json.Marshal(wrapper{Root: root, Files: files})
+	if err != nil {
+		return
+	}
+
+	bytes, err := c.query(ctx, "servers/"+id+"/files/compress", "POST", rq)
+	if err != nil {
+		return
+	}
+
+	var fWrapper struct {
+		File *FileInfo `json:"attributes"`
+	}
+
+	err = json.Unmarshal(bytes, &fWrapper)
+	if err != nil {
+		return
+	}
+
+	return fWrapper.File, nil
+}
+
+// DecompressFile extracts an archive inside root
+func (c *ClientCredentials) DecompressFile(id, root, file string) error {
+	return c.DecompressFileContext(context.Background(), id, root, file)
+}
+
+// DecompressFileContext is DecompressFile with a context
+func (c *ClientCredentials) DecompressFileContext(ctx context.Context, id, root, file string) (err error) {
+	type wrapper struct {
+		Root string `json:"root"`
+		File string `json:"file"`
+	}
+
+	bytes, err := json.Marshal(wrapper{Root: root, File: file})
+	if err != nil {
+		return
+	}
+
+	_, err = c.query(ctx, "servers/"+id+"/files/decompress", "POST", bytes)
+	return
+}
+
+// ChmodFiles changes the permissions of files of the server. The file paths are relative to root.
+func (c *ClientCredentials) ChmodFiles(id, root string, files ...FileChmod) error {
+	return c.ChmodFilesContext(context.Background(), id, root, files...)
+}
+
+// ChmodFilesContext is ChmodFiles with a context
+func (c *ClientCredentials) ChmodFilesContext(ctx context.Context, id, root string, files ...FileChmod) (err error) {
+	type wrapper struct {
+		Root  string      `json:"root"`
+		Files []FileChmod `json:"files"`
+	}
+
+	bytes, err := json.Marshal(wrapper{Root: root, Files: files})
+	if err != nil {
+		return
+	}
+
+	_, err = c.query(ctx, "servers/"+id+"/files/chmod", "POST", bytes)
+	return
+}
+
+// PullRemoteFile makes the daemon download the file at remoteURL into directory. The filename and options are
+// optional. Unless the pull runs in the foreground the file may not be in place yet when this returns. URLs or
+// names rejected by the daemon are returned as an APIError, see IsValidationError.
+func (c *ClientCredentials) PullRemoteFile(id, remoteURL, directory, filename string, opts *PullOptions) error {
+	return c.PullRemoteFileContext(context.Background(), id, remoteURL, directory, filename, opts)
+}
+
+// PullRemoteFileContext is PullRemoteFile with a context
+func (c *ClientCredentials) PullRemoteFileContext(ctx context.Context, id, remoteURL, directory, filename string,
+	opts *PullOptions) (err error) {
+	type wrapper struct {
+		URL        string `json:"url"`
+		Directory  string `json:"directory,omitempty"`
+		Filename   string `json:"filename,omitempty"`
+		UseHeader  bool   `json:"use_header,omitempty"`
+		Foreground bool   `json:"foreground,omitempty"`
+	}
+
+	if opts == nil {
+		opts = &PullOptions{}
+	}
+
+	rq := wrapper{
+		URL:        remoteURL,
+		Directory:  directory,
+		Filename:   filename,
+		UseHeader:  opts.UseHeader,
+		Foreground: opts.Foreground,
+	}
+
+	bytes, err := json.Marshal(rq)
+	if err != nil {
+		return
+	}
+
+	_, err = c.query(ctx, "servers/"+id+"/files/pull", "POST", bytes)
+	return
+}
@@ -0,0 +1,310 @@
+package fossil
+
+import (
+	"context"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+	"time"
+)
+
+//***** Testing *****//
+
+func TestClientCredentials_ListFiles(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/files/list?directory=%2Fplugins"
+		if expectURL != url {
+			t.Errorf("Request url does not match expected: %s", url)
+		}
+
+		res := `{
+		  "object": "list",
+		  "data": [
+			{
+			  "object": "file_object",
+			  "attributes": {
+				"name": "config",
+				"mode": "drwxr-xr-x",
+				"mode_bits": "755",
+				"size": 4096,
+				"is_file": false,
+				"is_symlink": false,
+				"mimetype": "inode/directory",
+				"created_at": "2020-07-18T13:04:01+00:00",
+				"modified_at": "2020-07-18T13:04:01+00:00"
+			  }
+			},
+			{
+			  "object": "file_object",
+			  "attributes": {
+				"name": "plugin.jar",
+				"mode": "-rw-r--r--",
+				"mode_bits": "644",
+				"size": 1048576,
+				"is_file": true,
+				"is_symlink": false,
+				"mimetype": "application/java-archive",
+				"created_at": "2020-07-18T13:04:01+00:00",
+				"modified_at": "2020-07-18T13:05:12+00:00"
+			  }
+			}
+		  ]
+		}`
+
+		return []byte(res), nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	created, _ := time.Parse(time.RFC3339, "2020-07-18T13:04:01+00:00")
+	modified, _ := time.Parse(time.RFC3339, "2020-07-18T13:05:12+00:00")
+
+	expect := []*FileInfo{
+		{
+			Name:       "config",
+			Mode:       "drwxr-xr-x",
+			ModeBits:   "755",
+			Size:       4096,
+			MimeType:   "inode/directory",
+			CreatedAt:  created,
+			ModifiedAt: created,
+		},
+		{
+			Name:       "plugin.jar",
+			Mode:       "-rw-r--r--",
+			ModeBits:   "644",
+			Size:       1048576,
+			IsFile:     true,
+			MimeType:   "application/java-archive",
+			CreatedAt:  created,
+			ModifiedAt: modified,
+		},
+	}
+
+	got, err := c.ListFiles("1a7ce997", "/plugins")
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	if !cmp.Equal(got, expect) {
+		t.Errorf("Unexpected response: %s", cmp.Diff(got, expect))
+	}
+
+	if !got[0].IsDir() || got[1].IsDir() {
+		t.Error("Directories not detected")
+	}
+}
+
+func TestClientCredentials_ReadFile(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/files/contents?file=%2Fserver.properties"
+		if expectURL != url {
+			t.Errorf("Request url does not match expected: %s", url)
+		}
+
+		return []byte("motd=Hello"), nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	got, err := c.ReadFile("1a7ce997", "/server.properties")
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	if string(got) != "motd=Hello" {
+		t.Errorf("Unexpected response: %s", got)
+	}
+}
+
+func TestClientCredentials_WriteFile(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/files/write?file=%2Fserver.properties"
+		if expectURL != url || method != "POST" {
+			t.Errorf("Request does not match expected: %s %s", method, url)
+		}
+
+		if string(data) != "motd=Hello" {
+			t.Errorf("Request data does not match expected: %s", string(data))
+		}
+
+		return nil, nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	err := c.WriteFile("1a7ce997", "/server.properties", []byte("motd=Hello"))
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+}
+
+func TestClientCredentials_RenameFiles(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/files/rename"
+		if expectURL != url || method != "PUT" {
+			t.Errorf("Request does not match expected: %s %s", method, url)
+		}
+
+		expectBody := `{"root":"/","files":[{"from":"old.txt","to":"archive/new.txt"}]}`
+		if expectBody != string(data) {
+			t.Errorf("Request data does not match expected: %s", string(data))
+		}
+
+		return nil, nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	err := c.RenameFiles("1a7ce997", "/", FileRename{From: "old.txt", To: "archive/new.txt"})
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+}
+
+func TestClientCredentials_DeleteFiles(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/files/delete"
+		if expectURL != url {
+			t.Errorf("Request url does not match expected: %s", url)
+		}
+
+		expectBody := `{"root":"/logs","files":["latest.log","old.log"]}`
+		if expectBody != string(data) {
+			t.Errorf("Request data does not match expected: %s", string(data))
+		}
+
+		return nil, nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	err := c.DeleteFiles("1a7ce997", "/logs", "latest.log", "old.log")
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+}
+
+func TestClientCredentials_CompressFiles(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/files/compress"
+		if expectURL != url {
+			t.Errorf("Request url does not match expected: %s", url)
+		}
+
+		expectBody := `{"root":"/","files":["world"]}`
+		if expectBody != string(data) {
+			t.Errorf("Request data does not match expected: %s", string(data))
+		}
+
+		res := `{
+		  "object": "file_object",
+		  "attributes": {
+			"name": "archive-2020-07-18T130401.tar.gz",
+			"size": 2048,
+			"is_file": true,
+			"mimetype": "application/gzip"
+		  }
+		}`
+
+		return []byte(res), nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	got, err := c.CompressFiles("1a7ce997", "/", "world")
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	if got.Name != "archive-2020-07-18T130401.tar.gz" {
+		t.Errorf("Unexpected response: %+v", got)
+	}
+}
+
+func TestClientCredentials_ChmodFiles(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/files/chmod"
+		if expectURL != url {
+			t.Errorf("Request url does not match expected: %s", url)
+		}
+
+		expectBody := `{"root":"/","files":[{"file":"start.sh","mode":"755"}]}`
+		if expectBody != string(data) {
+			t.Errorf("Request data does not match expected: %s", string(data))
+		}
+
+		return nil, nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	err := c.ChmodFiles("1a7ce997", "/", FileChmod{File: "start.sh", Mode: 0755})
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+}
+
+func TestClientCredentials_CreateFolder(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/files/create-folder"
+		if expectURL != url {
+			t.Errorf("Request url does not match expected: %s", url)
+		}
+
+		expectBody := `{"root":"/","name":"backups"}`
+		if expectBody != string(data) {
+			t.Errorf("Request data does not match expected: %s", string(data))
+		}
+
+		return nil, nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	err := c.CreateFolder("1a7ce997", "/", "backups")
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+}
+
+func TestClientCredentials_PullRemoteFile(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/files/pull"
+		if expectURL != url || method != "POST" {
+			t.Errorf("Request does not match expected: %s %s", method, url)
+		}
+
+		expectBody := `{"url":"https://artifacts.example.com/plugin.jar","directory":"/plugins","foreground":true}`
+		if expectBody != string(data) {
+			t.Errorf("Request data does not match expected: %s", string(data))
+		}
+
+		return nil, nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	err := c.PullRemoteFile("1a7ce997", "https://artifacts.example.com/plugin.jar", "/plugins", "",
+		&PullOptions{Foreground: true})
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+}
+
+func TestClientCredentials_PullRemoteFileInvalid(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		return nil, &APIError{
+			StatusCode: 422,
+			Errors: []*ErrorDetail{
+				{Code: "ValidationException", Status: "422", Detail: "The url format is invalid."},
+			},
+		}
+	}
+
+	c := NewClient("https://example.com", "")
+
+	err := c.PullRemoteFile("1a7ce997", "not a url", "/", "", nil)
+	if !IsValidationError(err) {
+		t.Errorf("Expected a validation error, got: %v", err)
+	}
+}
@@ -1,6 +1,11 @@
 // Package fossil provides a wrapper for the Pterodactyl and WISP APIs.
 package fossil
 
+import (
+	"net/http"
+	"time"
+)
+
 //***** Credentials *****//
 
 // Credentials is the base object for ClientCredentials and ApplicationCredentials, and should not be used
@@ -8,8 +13,22 @@ package fossil
 type Credentials struct {
 	URL   string
 	Token string
+
+	httpClient *http.Client
+	userAgent  string
+	headers    http.Header
+	retry      *RetryPolicy
+	limiter    *RateLimiter
+	statusAPI  int32 // Status endpoint supported by the panel, accessed atomically
 }
 
+// Status endpoints, as detected by GetServerStatus
+const (
+	statusAPIUnknown int32 = iota
+	statusAPIResources
+	statusAPIUtilization
+)
+
 // ClientCredentials are user-specific, and can only be used to access and modify servers associated
 // with that user. They do not allow administrator-level control of the servers.
 type ClientCredentials Credentials
@@ -18,18 +37,109 @@ type ClientCredentials Credentials
 // users with creation and destruction privileges.
 type ApplicationCredentials Credentials
 
+// defaultHTTPClient is shared by credentials that were not built with NewClient or NewApplication, so that
+// connections are still reused between requests.
+var defaultHTTPClient = &http.Client{}
+
 // NewClient creates a new ClientCredentials object used to interact with Pterodactyl as a user.
-func NewClient(url, clientToken string) *ClientCredentials {
-	return &ClientCredentials{
-		URL:   url,
-		Token: clientToken,
-	}
+func NewClient(url, clientToken string, opts ...Option) *ClientCredentials {
+	return (*ClientCredentials)(newCredentials(url, clientToken, opts))
 }
 
 // NewApplication Creates a new ApplicationCredentials object used to interact with Pterodactyl as administrator.
-func NewApplication(url, apiToken string) *ApplicationCredentials {
-	return &ApplicationCredentials{
-		URL:   url,
-		Token: apiToken,
+func NewApplication(url, apiToken string, opts ...Option) *ApplicationCredentials {
+	return (*ApplicationCredentials)(newCredentials(url, apiToken, opts))
+}
+
+// newCredentials builds the shared Credentials and applies the options over them
+func newCredentials(url, token string, opts []Option) *Credentials {
+	cfg := &config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	client := cfg.httpClient
+	if client == nil {
+		client = &http.Client{}
+	}
+
+	// The timeout is set on a copy so a client given through WithHTTPClient is never modified
+	if cfg.timeout > 0 {
+		c := *client
+		c.Timeout = cfg.timeout
+		client = &c
+	}
+
+	return &Credentials{
+		URL:        url,
+		Token:      token,
+		httpClient: client,
+		userAgent:  cfg.userAgent,
+		headers:    cfg.headers,
+		retry:      cfg.retry,
+		limiter:    cfg.limiter,
+	}
+}
+
+// client returns the HTTP client used to perform the requests
+func (c *Credentials) client() *http.Client {
+	if c.httpClient == nil {
+		return defaultHTTPClient
+	}
+
+	return c.httpClient
+}
+
+//***** Options *****//
+
+// Option modifies the behaviour of the credentials created with NewClient or NewApplication
+type Option func(*config)
+
+// config holds the settings collected from the options before the credentials are built
+type config struct {
+	httpClient *http.Client
+	timeout    time.Duration
+	userAgent  string
+	headers    http.Header
+	retry      *RetryPolicy
+	limiter    *RateLimiter
+}
+
+// WithHTTPClient sets the HTTP client used for every request. It allows the use of custom transports, proxies
+// or TLS settings.
+func WithHTTPClient(client *http.Client) Option {
+	return func(cfg *config) {
+		cfg.httpClient = client
+	}
+}
+
+// WithTimeout sets a time limit for every request, including the time spent reading the response body. File
+// uploads and downloads are not limited, as they can take arbitrarily long; use their context instead.
+func WithTimeout(timeout time.Duration) Option {
+	return func(cfg *config) {
+		cfg.timeout = timeout
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request
+func WithUserAgent(userAgent string) Option {
+	return func(cfg *config) {
+		cfg.userAgent = userAgent
+	}
+}
+
+// WithBaseHeaders adds headers to every request. The headers required by the API (Authorization, Accept and
+// Content-Type) can't be overridden.
+func WithBaseHeaders(headers http.Header) Option {
+	return func(cfg *config) {
+		if cfg.headers == nil {
+			cfg.headers = http.Header{}
+		}
+
+		for k, v := range headers {
+			for _, s := range v {
+				cfg.headers.Add(k, s)
+			}
+		}
 	}
 }
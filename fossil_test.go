@@ -1,6 +1,10 @@
 package fossil
 
-import "testing"
+import (
+	"net/http"
+	"testing"
+	"time"
+)
 
 //***** Testing *****//
 
@@ -13,3 +17,29 @@ func TestNewApplication(t *testing.T) {
 	// Panic check
 	NewApplication("www.example.com", "TESTTOKEN")
 }
+
+func TestNewApplication_Options(t *testing.T) {
+	hc := &http.Client{}
+	a := NewApplication("www.example.com", "TESTTOKEN",
+		WithHTTPClient(hc),
+		WithTimeout(5*time.Second),
+		WithUserAgent("fossil-test"),
+		WithBaseHeaders(http.Header{"X-Test": []string{"1"}}),
+	)
+
+	if a.httpClient.Timeout != 5*time.Second {
+		t.Errorf("Timeout not applied: %s", a.httpClient.Timeout)
+	}
+
+	if hc.Timeout != 0 {
+		t.Error("The provided HTTP client was modified")
+	}
+
+	if a.userAgent != "fossil-test" {
+		t.Errorf("User agent not applied: %s", a.userAgent)
+	}
+
+	if a.headers.Get("X-Test") != "1" {
+		t.Error("Base headers not applied")
+	}
+}
@@ -1,6 +1,7 @@
 package fossil
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -17,81 +18,66 @@ type Location struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
-// jsonLocationPage is a slate to correctly parse responses from the API into JSON
-type jsonLocationPage struct {
-	Data []struct {
-		Location *Location `json:"attributes"`
-	} `json:"data"`
-	Meta Meta `json:"meta"`
-}
+//***** Pagination *****//
 
-//***** Converters *****//
+// LocationIterator lazily walks a list of locations, fetching the pages as needed
+type LocationIterator struct {
+	pager
+	location *Location
+}
 
-// asUserSlice parses a jsonUserPage into a []*User
-func (lp *jsonLocationPage) asLocationSlice() (locations []*Location) {
-	for _, l := range lp.Data {
-		locations = append(locations, l.Location)
+// Next advances to the next location, returning false when there are no more or an error occurred
+func (it *LocationIterator) Next() bool {
+	var v Location
+	if !it.next(&v) {
+		return false
 	}
 
-	return locations
+	it.location = &v
+	return true
 }
 
-//***** Pagination *****//
-
-// getAll fetches all the existing pages for a location. The original page is kept as index 0
-func (lp *jsonLocationPage) getAll(token string) (pages []*jsonLocationPage, err error) {
-	pages = append(pages, lp)
-	for pages[len(pages)-1].Meta.Pagination.Links.Next != "" {
-		url := lp.Meta.Pagination.Links.Next
-		bytes, err := queryURL(url, token, "GET", nil)
-		if err != nil {
-			return nil, err
-		}
-
-		var page jsonLocationPage
-		err = json.Unmarshal(bytes, &page)
-		if err != nil {
-			return nil, err
-		}
-
-		pages = append(pages, &page)
-	}
-
-	return pages, nil
+// Value returns the current location
+func (it *LocationIterator) Value() *Location {
+	return it.location
 }
 
 //***** Requests *****//
 
-// GetLocations fetches all available locations
-func (c *ApplicationCredentials) GetLocations() (locations []*Location, err error) {
-	bytes, err := c.query("locations", "GET", nil)
-	if err != nil {
-		return
-	}
+// GetLocations fetches all available locations. The results can be optionally filtered and sorted with
+// ListOptions.
+func (c *ApplicationCredentials) GetLocations(opts ...*ListOptions) ([]*Location, error) {
+	return c.GetLocationsContext(context.Background(), opts...)
+}
 
-	// Get the initial page
-	var page jsonLocationPage
-	err = json.Unmarshal(bytes, &page)
-	if err != nil {
-		return
+// GetLocationsContext is GetLocations with a context
+func (c *ApplicationCredentials) GetLocationsContext(ctx context.Context, opts ...*ListOptions) (locations []*Location, err error) {
+	it := c.LocationsIterContext(ctx, listOptions(opts))
+	for it.Next() {
+		locations = append(locations, it.Value())
 	}
 
-	// Search for the remaining pages if present
-	pages, err := page.getAll(c.Token)
-	if err != nil {
-		return
-	}
+	return locations, it.Err()
+}
 
-	for _, page := range pages {
-		locations = append(locations, page.asLocationSlice()...)
-	}
+// LocationsIter returns an iterator over all the available locations. The pages are fetched as they are needed.
+func (c *ApplicationCredentials) LocationsIter(opts *ListOptions) *LocationIterator {
+	return c.LocationsIterContext(context.Background(), opts)
+}
 
-	return
+// LocationsIterContext is LocationsIter with a context
+func (c *ApplicationCredentials) LocationsIterContext(ctx context.Context, opts *ListOptions) *LocationIterator {
+	return &LocationIterator{pager: newPager(ctx, (*Credentials)(c), c.endpointURL("locations"), opts)}
 }
 
 // GetLocation fetches the location with the given ID
-func (c *ApplicationCredentials) GetLocation(id int) (loc *Location, err error) {
-	bytes, err := c.query(fmt.Sprintf("locations/%d", id), "GET", nil)
+func (c *ApplicationCredentials) GetLocation(id int) (*Location, error) {
+	return c.GetLocationContext(context.Background(), id)
+}
+
+// GetLocationContext is GetLocation with a context
+func (c *ApplicationCredentials) GetLocationContext(ctx context.Context, id int) (loc *Location, err error) {
+	bytes, err := c.query(ctx, fmt.Sprintf("locations/%d", id), "GET", nil)
 	if err != nil {
 		return
 	}
@@ -109,7 +95,12 @@ func (c *ApplicationCredentials) GetLocation(id int) (loc *Location, err error)
 }
 
 // CreateLocation makes a new location with the provided names
-func (c *ApplicationCredentials) CreateLocation(shortName, longName string) (loc *Location, err error) {
+func (c *ApplicationCredentials) CreateLocation(shortName, longName string) (*Location, error) {
+	return c.CreateLocationContext(context.Background(), shortName, longName)
+}
+
+// CreateLocationContext is CreateLocation with a context
+func (c *ApplicationCredentials) CreateLocationContext(ctx context.Context, shortName, longName string) (loc *Location, err error) {
 	type names struct {
 		Short string `json:"short"`
 		Long  string `json:"long"`
@@ -125,7 +116,7 @@ func (c *ApplicationCredentials) CreateLocation(shortName, longName string) (loc
 		return
 	}
 
-	bytes, err := c.query("locations", "POST", nms)
+	bytes, err := c.query(ctx, "locations", "POST", nms)
 	if err != nil {
 		return
 	}
@@ -143,7 +134,12 @@ func (c *ApplicationCredentials) CreateLocation(shortName, longName string) (loc
 }
 
 // UpdateLocationName modifies the short and long names of a location
-func (c *ApplicationCredentials) UpdateLocationName(loc *Location) (err error) {
+func (c *ApplicationCredentials) UpdateLocationName(loc *Location) error {
+	return c.UpdateLocationNameContext(context.Background(), loc)
+}
+
+// UpdateLocationNameContext is UpdateLocationName with a context
+func (c *ApplicationCredentials) UpdateLocationNameContext(ctx context.Context, loc *Location) (err error) {
 	type names struct {
 		Short string `json:"short"`
 		Long  string `json:"long"`
@@ -159,12 +155,17 @@ func (c *ApplicationCredentials) UpdateLocationName(loc *Location) (err error) {
 		return
 	}
 
-	_, err = c.query(fmt.Sprintf("locations/%d", loc.ID), "PATCH", nms)
+	_, err = c.query(ctx, fmt.Sprintf("locations/%d", loc.ID), "PATCH", nms)
 	return
 }
 
 // DeleteLocation marks a server as suspended
-func (c *ApplicationCredentials) DeleteLocation(lid int) (err error) {
-	_, err = c.query(fmt.Sprintf("locations/%d", lid), "DELETE", nil)
+func (c *ApplicationCredentials) DeleteLocation(lid int) error {
+	return c.DeleteLocationContext(context.Background(), lid)
+}
+
+// DeleteLocationContext is DeleteLocation with a context
+func (c *ApplicationCredentials) DeleteLocationContext(ctx context.Context, lid int) (err error) {
+	_, err = c.query(ctx, fmt.Sprintf("locations/%d", lid), "DELETE", nil)
 	return
 }
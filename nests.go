@@ -1,6 +1,7 @@
 package fossil
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -19,15 +20,6 @@ type Nest struct {
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
-// jsonNestPage is a slate to correctly parse responses from the API into JSON
-type jsonNestPage struct {
-	Data []struct {
-		Object string `json:"object"`
-		Nest   *Nest  `json:"attributes"`
-	} `json:"data"`
-	Meta Meta `json:"meta"`
-}
-
 // Egg represents the information regarding an egg
 type Egg struct {
 	ID          int       `json:"id"`
@@ -66,73 +58,65 @@ type EggScript struct {
 	Extends    string `json:"extends"`
 }
 
-//***** Converters *****//
-
-// asUserSlice parses a jsonUserPage into a []*User
-func (np *jsonNestPage) asNestSlice() (nests []*Nest) {
-	for _, n := range np.Data {
-		nests = append(nests, n.Nest)
-	}
+//***** Pagination *****//
 
-	return nests
+// NestIterator lazily walks a list of nests, fetching the pages as needed
+type NestIterator struct {
+	pager
+	nest *Nest
 }
 
-//***** Pagination *****//
-
-// getAll fetches all the existing pages for a nest. The original page is kept as index 0
-func (np *jsonNestPage) getAll(token string) (pages []*jsonNestPage, err error) {
-	pages = append(pages, np)
-	for pages[len(pages)-1].Meta.Pagination.Links.Next != "" {
-		url := np.Meta.Pagination.Links.Next
-		bytes, err := queryURL(url, token, "GET", nil)
-		if err != nil {
-			return nil, err
-		}
-
-		var page jsonNestPage
-		err = json.Unmarshal(bytes, &page)
-		if err != nil {
-			return nil, err
-		}
-
-		pages = append(pages, &page)
+// Next advances to the next nest, returning false when there are no more or an error occurred
+func (it *NestIterator) Next() bool {
+	var v Nest
+	if !it.next(&v) {
+		return false
 	}
 
-	return pages, nil
+	it.nest = &v
+	return true
+}
+
+// Value returns the current nest
+func (it *NestIterator) Value() *Nest {
+	return it.nest
 }
 
 //***** Requests *****//
 
-// GetNests fetches all available nests
-func (c *ApplicationCredentials) GetNests() (nests []*Nest, err error) {
-	bytes, err := c.query("nests", "GET", nil)
-	if err != nil {
-		return
-	}
+// GetNests fetches all available nests. The results can be optionally sorted with ListOptions.
+func (c *ApplicationCredentials) GetNests(opts ...*ListOptions) ([]*Nest, error) {
+	return c.GetNestsContext(context.Background(), opts...)
+}
 
-	// Get the initial page
-	var page jsonNestPage
-	err = json.Unmarshal(bytes, &page)
-	if err != nil {
-		return
+// GetNestsContext is GetNests with a context
+func (c *ApplicationCredentials) GetNestsContext(ctx context.Context, opts ...*ListOptions) (nests []*Nest, err error) {
+	it := c.NestsIterContext(ctx, listOptions(opts))
+	for it.Next() {
+		nests = append(nests, it.Value())
 	}
 
-	// Search for the remaining pages if present
-	pages, err := page.getAll(c.Token)
-	if err != nil {
-		return
-	}
+	return nests, it.Err()
+}
 
-	for _, page := range pages {
-		nests = append(nests, page.asNestSlice()...)
-	}
+// NestsIter returns an iterator over all the available nests. The pages are fetched as they are needed.
+func (c *ApplicationCredentials) NestsIter(opts *ListOptions) *NestIterator {
+	return c.NestsIterContext(context.Background(), opts)
+}
 
-	return
+// NestsIterContext is NestsIter with a context
+func (c *ApplicationCredentials) NestsIterContext(ctx context.Context, opts *ListOptions) *NestIterator {
+	return &NestIterator{pager: newPager(ctx, (*Credentials)(c), c.endpointURL("nests"), opts)}
 }
 
 // GetNest fetches, if present, a specific nest.
-func (c *ApplicationCredentials) GetNest(id int) (nest *Nest, err error) {
-	bytes, err := c.query(fmt.Sprintf("nests/%d", id), "GET", nil)
+func (c *ApplicationCredentials) GetNest(id int) (*Nest, error) {
+	return c.GetNestContext(context.Background(), id)
+}
+
+// GetNestContext is GetNest with a context
+func (c *ApplicationCredentials) GetNestContext(ctx context.Context, id int) (nest *Nest, err error) {
+	bytes, err := c.query(ctx, fmt.Sprintf("nests/%d", id), "GET", nil)
 	if err != nil {
 		return
 	}
@@ -150,8 +134,13 @@ func (c *ApplicationCredentials) GetNest(id int) (nest *Nest, err error) {
 }
 
 // GetEggs fetches all eggs inside a nest
-func (c *ApplicationCredentials) GetEggs(nestID int) (eggs []*Egg, err error) {
-	bytes, err := c.query(fmt.Sprintf("nests/%d/eggs", nestID), "GET", nil)
+func (c *ApplicationCredentials) GetEggs(nestID int) ([]*Egg, error) {
+	return c.GetEggsContext(context.Background(), nestID)
+}
+
+// GetEggsContext is GetEggs with a context
+func (c *ApplicationCredentials) GetEggsContext(ctx context.Context, nestID int) (eggs []*Egg, err error) {
+	bytes, err := c.query(ctx, fmt.Sprintf("nests/%d/eggs", nestID), "GET", nil)
 	if err != nil {
 		return
 	}
@@ -175,8 +164,13 @@ func (c *ApplicationCredentials) GetEggs(nestID int) (eggs []*Egg, err error) {
 }
 
 // GetEgg searches for a specific eggs inside a nest
-func (c *ApplicationCredentials) GetEgg(nestID int, eggID int) (egg *Egg, err error) {
-	bytes, err := c.query(fmt.Sprintf("nests/%d/eggs/%d", nestID, eggID), "GET", nil)
+func (c *ApplicationCredentials) GetEgg(nestID int, eggID int) (*Egg, error) {
+	return c.GetEggContext(context.Background(), nestID, eggID)
+}
+
+// GetEggContext is GetEgg with a context
+func (c *ApplicationCredentials) GetEggContext(ctx context.Context, nestID int, eggID int) (egg *Egg, err error) {
+	bytes, err := c.query(ctx, fmt.Sprintf("nests/%d/eggs/%d", nestID, eggID), "GET", nil)
 	if err != nil {
 		return
 	}
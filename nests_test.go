@@ -1,6 +1,7 @@
 package fossil
 
 import (
+	"context"
 	"github.com/google/go-cmp/cmp"
 	"testing"
 	"time"
@@ -9,7 +10,7 @@ import (
 //***** Testing *****//
 
 func TestApplicationCredentials_GetNests(t *testing.T) {
-	query = func(url, token, method string, data []byte) ([]byte, error) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
 		expectURL := "https://example.com/api/application/nests"
 		if expectURL != url {
 			t.Errorf("Request url does not match expected: %s", url)
@@ -146,7 +147,7 @@ func TestApplicationCredentials_GetNests(t *testing.T) {
 }
 
 func TestApplicationCredentials_GetNest(t *testing.T) {
-	query = func(url, token, method string, data []byte) ([]byte, error) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
 		expectURL := "https://example.com/api/application/nests/1"
 		if expectURL != url {
 			t.Errorf("Request url does not match expected: %s", url)
@@ -194,7 +195,7 @@ func TestApplicationCredentials_GetNest(t *testing.T) {
 }
 
 func TestApplicationCredentials_GetEggs(t *testing.T) {
-	query = func(url, token, method string, data []byte) ([]byte, error) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
 		expectURL := "https://example.com/api/application/nests/1/eggs"
 		if expectURL != url {
 			t.Errorf("Request url does not match expected: %s", url)
@@ -377,7 +378,7 @@ func TestApplicationCredentials_GetEggs(t *testing.T) {
 }
 
 func TestApplicationCredentials_GetEgg(t *testing.T) {
-	query = func(url, token, method string, data []byte) ([]byte, error) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
 		expectURL := "https://example.com/api/application/nests/1/eggs/1"
 		if expectURL != url {
 			t.Errorf("Request url does not match expected: %s", url)
@@ -0,0 +1,124 @@
+package fossil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+//***** Requests *****//
+
+// ListAllocations fetches the network allocations assigned to a server
+func (c *ClientCredentials) ListAllocations(id string) ([]*Allocation, error) {
+	return c.ListAllocationsContext(context.Background(), id)
+}
+
+// ListAllocationsContext is ListAllocations with a context
+func (c *ClientCredentials) ListAllocationsContext(ctx context.Context, id string) (allocs []*Allocation, err error) {
+	bytes, err := c.query(ctx, "servers/"+id+"/network/allocations", "GET", nil)
+	if err != nil {
+		return
+	}
+
+	var wrapper struct {
+		Data []struct {
+			Allocation *Allocation `json:"attributes"`
+		} `json:"data"`
+	}
+
+	err = json.Unmarshal(bytes, &wrapper)
+	if err != nil {
+		return
+	}
+
+	for _, d := range wrapper.Data {
+		allocs = append(allocs, d.Allocation)
+	}
+
+	return allocs, nil
+}
+
+// AssignAllocation assigns a free allocation of the node to the server, and returns it. The panel picks the
+// allocation, and refuses if the server reached its allocation limit or automatic assignment is disabled.
+func (c *ClientCredentials) AssignAllocation(id string) (*Allocation, error) {
+	return c.AssignAllocationContext(context.Background(), id)
+}
+
+// AssignAllocationContext is AssignAllocation with a context
+func (c *ClientCredentials) AssignAllocationContext(ctx context.Context, id string) (*Allocation, error) {
+	bytes, err := c.query(ctx, "servers/"+id+"/network/allocations", "POST", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeAllocation(bytes)
+}
+
+// SetAllocationNotes sets the notes of an allocation, and returns the updated allocation. Empty notes clear them.
+func (c *ClientCredentials) SetAllocationNotes(id string, allocationID int, notes string) (*Allocation, error) {
+	return c.SetAllocationNotesContext(context.Background(), id, allocationID, notes)
+}
+
+// SetAllocationNotesContext is SetAllocationNotes with a context
+func (c *ClientCredentials) SetAllocationNotesContext(ctx context.Context, id string, allocationID int,
+	notes string) (*Allocation, error) {
+	type wrapper struct {
+		Notes string `json:"notes"`
+	}
+
+	rq, err := json.Marshal(wrapper{Notes: notes})
+	if err != nil {
+		return nil, err
+	}
+
+	bytes, err := c.query(ctx, fmt.Sprintf("servers/%s/network/allocations/%d", id, allocationID), "POST", rq)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeAllocation(bytes)
+}
+
+// SetPrimaryAllocation makes an allocation the primary one of the server, and returns the updated allocation
+func (c *ClientCredentials) SetPrimaryAllocation(id string, allocationID int) (*Allocation, error) {
+	return c.SetPrimaryAllocationContext(context.Background(), id, allocationID)
+}
+
+// SetPrimaryAllocationContext is SetPrimaryAllocation with a context
+func (c *ClientCredentials) SetPrimaryAllocationContext(ctx context.Context, id string,
+	allocationID int) (*Allocation, error) {
+	bytes, err := c.query(ctx, fmt.Sprintf("servers/%s/network/allocations/%d/primary", id, allocationID),
+		"POST", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeAllocation(bytes)
+}
+
+// DeleteAllocation unassigns an allocation from the server. The primary allocation can't be unassigned.
+func (c *ClientCredentials) DeleteAllocation(id string, allocationID int) error {
+	return c.DeleteAllocationContext(context.Background(), id, allocationID)
+}
+
+// DeleteAllocationContext is DeleteAllocation with a context
+func (c *ClientCredentials) DeleteAllocationContext(ctx context.Context, id string, allocationID int) (err error) {
+	_, err = c.query(ctx, fmt.Sprintf("servers/%s/network/allocations/%d", id, allocationID), "DELETE", nil)
+	return
+}
+
+//***** Converters *****//
+
+// decodeAllocation parses a single allocation response
+func decodeAllocation(bytes []byte) (*Allocation, error) {
+	var wrapper struct {
+		Allocation *Allocation `json:"attributes"`
+	}
+
+	err := json.Unmarshal(bytes, &wrapper)
+	if err != nil {
+		return nil, err
+	}
+
+	return wrapper.Allocation, nil
+}
@@ -0,0 +1,164 @@
+package fossil
+
+import (
+	"context"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+)
+
+//***** Testing *****//
+
+func TestClientCredentials_ListAllocations(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/network/allocations"
+		if expectURL != url {
+			t.Errorf("Request url does not match expected: %s", url)
+		}
+
+		res := `{"object":"list","data":[{
+		  "object": "allocation",
+		  "attributes": {
+			"id": 1,
+			"ip": "45.86.168.218",
+			"ip_alias": "play.example.com",
+			"port": 25565,
+			"notes": "Game port",
+			"is_default": true
+		  }
+		},
+			{"object":"allocation","attributes":{"id":2,"ip":"45.86.168.218","ip_alias":null,"port":25566,
+			"notes":null,"is_default":false}}]}`
+
+		return []byte(res), nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	got, err := c.ListAllocations("1a7ce997")
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	expect := []*Allocation{
+		{ID: 1, Primary: true, IP: "45.86.168.218", Alias: "play.example.com", Port: 25565, Notes: "Game port"},
+		{ID: 2, IP: "45.86.168.218", Port: 25566},
+	}
+
+	if !cmp.Equal(got, expect) {
+		t.Errorf("Unexpected response: %s", cmp.Diff(got, expect))
+	}
+}
+
+func TestClientCredentials_AssignAllocation(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/network/allocations"
+		if expectURL != url || method != "POST" {
+			t.Errorf("Request url does not match expected: %s %s", method, url)
+		}
+
+		return []byte(`{"object":"allocation","attributes":{"id":3,"ip":"45.86.168.218","port":25567,
+			"is_default":false}}`), nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	got, err := c.AssignAllocation("1a7ce997")
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	expect := &Allocation{ID: 3, IP: "45.86.168.218", Port: 25567}
+	if !cmp.Equal(got, expect) {
+		t.Errorf("Unexpected response: %s", cmp.Diff(got, expect))
+	}
+}
+
+func TestClientCredentials_SetAllocationNotes(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/network/allocations/1"
+		if expectURL != url || method != "POST" {
+			t.Errorf("Request url does not match expected: %s %s", method, url)
+		}
+
+		if string(data) != `{"notes":"Game port"}` {
+			t.Errorf("Request body does not match expected: %s", data)
+		}
+
+		res := `{
+		  "object": "allocation",
+		  "attributes": {
+			"id": 1,
+			"ip": "45.86.168.218",
+			"ip_alias": "play.example.com",
+			"port": 25565,
+			"notes": "Game port",
+			"is_default": true
+		  }
+		}`
+
+		return []byte(res), nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	got, err := c.SetAllocationNotes("1a7ce997", 1, "Game port")
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	if got.Notes != "Game port" {
+		t.Errorf("Unexpected response: %+v", got)
+	}
+}
+
+func TestClientCredentials_SetPrimaryAllocation(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/network/allocations/1/primary"
+		if expectURL != url || method != "POST" {
+			t.Errorf("Request url does not match expected: %s %s", method, url)
+		}
+
+		res := `{
+		  "object": "allocation",
+		  "attributes": {
+			"id": 1,
+			"ip": "45.86.168.218",
+			"ip_alias": "play.example.com",
+			"port": 25565,
+			"notes": "Game port",
+			"is_default": true
+		  }
+		}`
+
+		return []byte(res), nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	got, err := c.SetPrimaryAllocation("1a7ce997", 1)
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	if !got.Primary {
+		t.Errorf("Unexpected response: %+v", got)
+	}
+}
+
+func TestClientCredentials_DeleteAllocation(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/network/allocations/2"
+		if expectURL != url || method != "DELETE" {
+			t.Errorf("Request url does not match expected: %s %s", method, url)
+		}
+
+		return nil, nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	err := c.DeleteAllocation("1a7ce997", 2)
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+}
@@ -0,0 +1,329 @@
+package fossil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+//***** Structures *****//
+
+// Node represents a machine running the daemon, where the servers are hosted
+type Node struct {
+	ID                 int       `json:"id"`
+	UUID               string    `json:"uuid"`
+	Public             bool      `json:"public"`
+	Name               string    `json:"name"`
+	Description        string    `json:"description"`
+	LocationID         int       `json:"location_id"`
+	FQDN               string    `json:"fqdn"`
+	Scheme             string    `json:"scheme"`
+	BehindProxy        bool      `json:"behind_proxy"`
+	MaintenanceMode    bool      `json:"maintenance_mode"`
+	Memory             int       `json:"memory"`
+	MemoryOverallocate int       `json:"memory_overallocate"`
+	Disk               int       `json:"disk"`
+	DiskOverallocate   int       `json:"disk_overallocate"`
+	UploadSize         int       `json:"upload_size"`
+	DaemonListen       int       `json:"daemon_listen"`
+	DaemonSFTP         int       `json:"daemon_sftp"`
+	DaemonBase         string    `json:"daemon_base"`
+	Location           *Location `json:"-"` // Only set when fetched from the API
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// WingsConfiguration is the configuration of the Wings daemon of a node, as generated by the panel. It can be
+// rendered with YAML and written to the node as config.yml.
+type WingsConfiguration struct {
+	Debug         bool        `json:"debug" yaml:"debug"`
+	UUID          string      `json:"uuid" yaml:"uuid"`
+	TokenID       string      `json:"token_id" yaml:"token_id"`
+	Token         string      `json:"token" yaml:"token"`
+	API           WingsAPI    `json:"api" yaml:"api"`
+	System        WingsSystem `json:"system" yaml:"system"`
+	AllowedMounts []string    `json:"allowed_mounts" yaml:"allowed_mounts"`
+	Remote        string      `json:"remote" yaml:"remote"`
+}
+
+// WingsAPI holds the settings of the HTTP API exposed by the daemon
+type WingsAPI struct {
+	Host        string   `json:"host" yaml:"host"`
+	Port        int      `json:"port" yaml:"port"`
+	SSL         WingsSSL `json:"ssl" yaml:"ssl"`
+	UploadLimit int      `json:"upload_limit" yaml:"upload_limit"`
+}
+
+// WingsSSL holds the certificate settings of the daemon API
+type WingsSSL struct {
+	Enabled bool   `json:"enabled" yaml:"enabled"`
+	Cert    string `json:"cert" yaml:"cert"`
+	Key     string `json:"key" yaml:"key"`
+}
+
+// WingsSystem holds the data directory and SFTP settings of the daemon
+type WingsSystem struct {
+	Data string `json:"data" yaml:"data"`
+	SFTP struct {
+		BindPort int `json:"bind_port" yaml:"bind_port"`
+	} `json:"sftp" yaml:"sftp"`
+}
+
+// jsonNode is the API definition for the node, including its relationships.
+// It's used as the target struct in the unmarshalling of API responses.
+type jsonNode struct {
+	Node
+	Relationships struct {
+		Location struct {
+			Location *Location `json:"attributes"`
+		} `json:"location"`
+	} `json:"relationships"`
+}
+
+// jsonNodeCreation stores the node info in an API-ready format for node creation and modification
+type jsonNodeCreation struct {
+	Name               string `json:"name"`
+	Description        string `json:"description,omitempty"`
+	LocationID         int    `json:"location_id"`
+	Public             bool   `json:"public"`
+	FQDN               string `json:"fqdn"`
+	Scheme             string `json:"scheme"`
+	BehindProxy        bool   `json:"behind_proxy"`
+	MaintenanceMode    bool   `json:"maintenance_mode"`
+	Memory             int    `json:"memory"`
+	MemoryOverallocate int    `json:"memory_overallocate"`
+	Disk               int    `json:"disk"`
+	DiskOverallocate   int    `json:"disk_overallocate"`
+	UploadSize         int    `json:"upload_size,omitempty"`
+	DaemonListen       int    `json:"daemon_listen"`
+	DaemonSFTP         int    `json:"daemon_sftp"`
+	DaemonBase         string `json:"daemon_base,omitempty"`
+}
+
+//***** Converters *****//
+
+// asNode parses a jsonNode into a *Node
+func (n *jsonNode) asNode() *Node {
+	node := n.Node
+	node.Location = n.Relationships.Location.Location
+
+	return &node
+}
+
+// asJSONNodeCreation parses a Node into a JSON-ready *jsonNodeCreation
+func (n *Node) asJSONNodeCreation() *jsonNodeCreation {
+	return &jsonNodeCreation{
+		Name:               n.Name,
+		Description:        n.Description,
+		LocationID:         n.LocationID,
+		Public:             n.Public,
+		FQDN:               n.FQDN,
+		Scheme:             n.Scheme,
+		BehindProxy:        n.BehindProxy,
+		MaintenanceMode:    n.MaintenanceMode,
+		Memory:             n.Memory,
+		MemoryOverallocate: n.MemoryOverallocate,
+		Disk:               n.Disk,
+		DiskOverallocate:   n.DiskOverallocate,
+		UploadSize:         n.UploadSize,
+		DaemonListen:       n.DaemonListen,
+		DaemonSFTP:         n.DaemonSFTP,
+		DaemonBase:         n.DaemonBase,
+	}
+}
+
+// YAML renders the configuration in the format expected by Wings
+func (w *WingsConfiguration) YAML() ([]byte, error) {
+	return yaml.Marshal(w)
+}
+
+//***** String *****//
+
+func (n *Node) String() string {
+	return n.Name
+}
+
+//***** Pagination *****//
+
+// NodeIterator lazily walks a list of nodes, fetching the pages as needed
+type NodeIterator struct {
+	pager
+	node *jsonNode
+}
+
+// Next advances to the next node, returning false when there are no more or an error occurred
+func (it *NodeIterator) Next() bool {
+	var v jsonNode
+	if !it.next(&v) {
+		return false
+	}
+
+	it.node = &v
+	return true
+}
+
+// Value returns the current node
+func (it *NodeIterator) Value() *Node {
+	return it.node.asNode()
+}
+
+//***** Requests *****//
+
+// GetNodes fetches all the nodes along with their location. The results can be optionally filtered and sorted
+// with ListOptions.
+func (c *ApplicationCredentials) GetNodes(opts ...*ListOptions) ([]*Node, error) {
+	return c.GetNodesContext(context.Background(), opts...)
+}
+
+// GetNodesContext is GetNodes with a context
+func (c *ApplicationCredentials) GetNodesContext(ctx context.Context, opts ...*ListOptions) (nodes []*Node, err error) {
+	it := c.NodesIterContext(ctx, listOptions(opts))
+	for it.Next() {
+		nodes = append(nodes, it.Value())
+	}
+
+	return nodes, it.Err()
+}
+
+// NodesIter returns an iterator over all the nodes. The pages are fetched as they are needed.
+func (c *ApplicationCredentials) NodesIter(opts *ListOptions) *NodeIterator {
+	return c.NodesIterContext(context.Background(), opts)
+}
+
+// NodesIterContext is NodesIter with a context
+func (c *ApplicationCredentials) NodesIterContext(ctx context.Context, opts *ListOptions) *NodeIterator {
+	return &NodeIterator{pager: newPager(ctx, (*Credentials)(c), c.endpointURL("nodes?include=location"), opts)}
+}
+
+// FindDeployableNodes fetches the nodes with enough free memory and disk (in MB) for a new server, optionally
+// restricted to the given locations. Nodes in maintenance mode or without free capacity are left out.
+func (c *ApplicationCredentials) FindDeployableNodes(memory, disk int, locationIDs ...int) ([]*Node, error) {
+	return c.FindDeployableNodesContext(context.Background(), memory, disk, locationIDs...)
+}
+
+// FindDeployableNodesContext is FindDeployableNodes with a context
+func (c *ApplicationCredentials) FindDeployableNodesContext(ctx context.Context, memory, disk int,
+	locationIDs ...int) (nodes []*Node, err error) {
+	q := url.Values{}
+	q.Set("memory", strconv.Itoa(memory))
+	q.Set("disk", strconv.Itoa(disk))
+	for _, id := range locationIDs {
+		q.Add("location_ids[]", strconv.Itoa(id))
+	}
+
+	target := c.endpointURL("nodes/deployable?" + q.Encode())
+
+	it := &NodeIterator{pager: newPager(ctx, (*Credentials)(c), target, nil)}
+	for it.Next() {
+		nodes = append(nodes, it.Value())
+	}
+
+	return nodes, it.Err()
+}
+
+// GetNode fetches the node with the given ID if it exists
+func (c *ApplicationCredentials) GetNode(id int) (*Node, error) {
+	return c.GetNodeContext(context.Background(), id)
+}
+
+// GetNodeContext is GetNode with a context
+func (c *ApplicationCredentials) GetNodeContext(ctx context.Context, id int) (node *Node, err error) {
+	bytes, err := c.query(ctx, fmt.Sprintf("nodes/%d?include=location", id), "GET", nil)
+	if err != nil {
+		return
+	}
+
+	var wrapper struct {
+		Node jsonNode `json:"attributes"`
+	}
+
+	err = json.Unmarshal(bytes, &wrapper)
+	if err != nil {
+		return
+	}
+
+	return wrapper.Node.asNode(), nil
+}
+
+// CreateNode makes a new node with the provided data and returns it as created by the panel
+func (c *ApplicationCredentials) CreateNode(n *Node) (*Node, error) {
+	return c.CreateNodeContext(context.Background(), n)
+}
+
+// CreateNodeContext is CreateNode with a context
+func (c *ApplicationCredentials) CreateNodeContext(ctx context.Context, n *Node) (node *Node, err error) {
+	rq, err := json.Marshal(n.asJSONNodeCreation())
+	if err != nil {
+		return
+	}
+
+	bytes, err := c.query(ctx, "nodes", "POST", rq)
+	if err != nil {
+		return
+	}
+
+	var wrapper struct {
+		Node jsonNode `json:"attributes"`
+	}
+
+	err = json.Unmarshal(bytes, &wrapper)
+	if err != nil {
+		return
+	}
+
+	return wrapper.Node.asNode(), nil
+}
+
+// UpdateNode modifies the node as per the passed object. The ID, UUID and dates can't be modified.
+func (c *ApplicationCredentials) UpdateNode(n *Node) error {
+	return c.UpdateNodeContext(context.Background(), n)
+}
+
+// UpdateNodeContext is UpdateNode with a context
+func (c *ApplicationCredentials) UpdateNodeContext(ctx context.Context, n *Node) (err error) {
+	bytes, err := json.Marshal(n.asJSONNodeCreation())
+	if err != nil {
+		return
+	}
+
+	_, err = c.query(ctx, fmt.Sprintf("nodes/%d", n.ID), "PATCH", bytes)
+	return
+}
+
+// DeleteNode deletes a node. The panel refuses to delete nodes with servers still on them.
+func (c *ApplicationCredentials) DeleteNode(id int) error {
+	return c.DeleteNodeContext(context.Background(), id)
+}
+
+// DeleteNodeContext is DeleteNode with a context
+func (c *ApplicationCredentials) DeleteNodeContext(ctx context.Context, id int) (err error) {
+	_, err = c.query(ctx, fmt.Sprintf("nodes/%d", id), "DELETE", nil)
+	return
+}
+
+// GetNodeConfiguration fetches the Wings configuration of a node. The configuration contains the daemon token, and
+// should be handled as a secret.
+func (c *ApplicationCredentials) GetNodeConfiguration(nodeID int) (*WingsConfiguration, error) {
+	return c.GetNodeConfigurationContext(context.Background(), nodeID)
+}
+
+// GetNodeConfigurationContext is GetNodeConfiguration with a context
+func (c *ApplicationCredentials) GetNodeConfigurationContext(ctx context.Context,
+	nodeID int) (cfg *WingsConfiguration, err error) {
+	bytes, err := c.query(ctx, fmt.Sprintf("nodes/%d/configuration", nodeID), "GET", nil)
+	if err != nil {
+		return
+	}
+
+	// The configuration is given as is, without the usual attributes wrapper
+	err = json.Unmarshal(bytes, &cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,561 @@
+package fossil
+
+import (
+	"context"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+	"time"
+)
+
+//***** Testing *****//
+
+func TestApplicationCredentials_GetNodes(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/application/nodes?include=location"
+		if expectURL != url {
+			t.Errorf("Request url does not match expected: %s", url)
+		}
+
+		res := `{
+		  "object": "list",
+		  "data": [{
+		  "object": "node",
+		  "attributes": {
+			"id": 1,
+			"uuid": "1046d1d1-b8ef-4771-82b1-2b5946d33397",
+			"public": true,
+			"name": "Test",
+			"description": "",
+			"location_id": 1,
+			"fqdn": "pterodactyl.test",
+			"scheme": "https",
+			"behind_proxy": false,
+			"maintenance_mode": false,
+			"memory": 2048,
+			"memory_overallocate": -1,
+			"disk": 5000,
+			"disk_overallocate": 0,
+			"upload_size": 100,
+			"daemon_listen": 8080,
+			"daemon_sftp": 2022,
+			"daemon_base": "/srv/daemon-data",
+			"created_at": "2019-12-22T04:44:51+00:00",
+			"updated_at": "2019-12-22T04:44:51+00:00",
+			"relationships": {
+			  "location": {
+				"object": "location",
+				"attributes": {
+				  "id": 1,
+				  "short": "test",
+				  "long": "Test Location",
+				  "updated_at": "2019-12-22T04:44:51+00:00",
+				  "created_at": "2019-12-22T04:44:51+00:00"
+				}
+			  }
+			}
+		  }
+		}],
+		  "meta": {
+			"pagination": {
+			  "total": 1,
+			  "count": 1,
+			  "per_page": 50,
+			  "current_page": 1,
+			  "total_pages": 1,
+			  "links": {}
+			}
+		  }
+		}`
+
+		return []byte(res), nil
+	}
+
+	a := NewApplication("https://example.com", "")
+
+	got, err := a.GetNodes()
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	d, _ := time.Parse(time.RFC3339, "2019-12-22T04:44:51+00:00")
+
+	expect := []*Node{
+		{
+			ID:                 1,
+			UUID:               "1046d1d1-b8ef-4771-82b1-2b5946d33397",
+			Public:             true,
+			Name:               "Test",
+			LocationID:         1,
+			FQDN:               "pterodactyl.test",
+			Scheme:             "https",
+			Memory:             2048,
+			MemoryOverallocate: -1,
+			Disk:               5000,
+			UploadSize:         100,
+			DaemonListen:       8080,
+			DaemonSFTP:         2022,
+			DaemonBase:         "/srv/daemon-data",
+			Location: &Location{
+				ID:        1,
+				ShortName: "test",
+				LongName:  "Test Location",
+				UpdatedAt: d,
+				CreatedAt: d,
+			},
+			CreatedAt: d,
+			UpdatedAt: d,
+		},
+	}
+
+	if !cmp.Equal(got, expect) {
+		t.Errorf("Unexpected response: %s", cmp.Diff(got, expect))
+	}
+}
+
+func TestApplicationCredentials_GetNode(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/application/nodes/1?include=location"
+		if expectURL != url {
+			t.Errorf("Request url does not match expected: %s", url)
+		}
+
+		res := `{
+		  "object": "node",
+		  "attributes": {
+			"id": 1,
+			"uuid": "1046d1d1-b8ef-4771-82b1-2b5946d33397",
+			"public": true,
+			"name": "Test",
+			"description": "",
+			"location_id": 1,
+			"fqdn": "pterodactyl.test",
+			"scheme": "https",
+			"behind_proxy": false,
+			"maintenance_mode": false,
+			"memory": 2048,
+			"memory_overallocate": -1,
+			"disk": 5000,
+			"disk_overallocate": 0,
+			"upload_size": 100,
+			"daemon_listen": 8080,
+			"daemon_sftp": 2022,
+			"daemon_base": "/srv/daemon-data",
+			"created_at": "2019-12-22T04:44:51+00:00",
+			"updated_at": "2019-12-22T04:44:51+00:00",
+			"relationships": {
+			  "location": {
+				"object": "location",
+				"attributes": {
+				  "id": 1,
+				  "short": "test",
+				  "long": "Test Location",
+				  "updated_at": "2019-12-22T04:44:51+00:00",
+				  "created_at": "2019-12-22T04:44:51+00:00"
+				}
+			  }
+			}
+		  }
+		}`
+
+		return []byte(res), nil
+	}
+
+	a := NewApplication("https://example.com", "")
+
+	got, err := a.GetNode(1)
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	d, _ := time.Parse(time.RFC3339, "2019-12-22T04:44:51+00:00")
+
+	expect := &Node{
+		ID:                 1,
+		UUID:               "1046d1d1-b8ef-4771-82b1-2b5946d33397",
+		Public:             true,
+		Name:               "Test",
+		LocationID:         1,
+		FQDN:               "pterodactyl.test",
+		Scheme:             "https",
+		Memory:             2048,
+		MemoryOverallocate: -1,
+		Disk:               5000,
+		UploadSize:         100,
+		DaemonListen:       8080,
+		DaemonSFTP:         2022,
+		DaemonBase:         "/srv/daemon-data",
+		Location: &Location{
+			ID:        1,
+			ShortName: "test",
+			LongName:  "Test Location",
+			UpdatedAt: d,
+			CreatedAt: d,
+		},
+		CreatedAt: d,
+		UpdatedAt: d,
+	}
+
+	if !cmp.Equal(got, expect) {
+		t.Errorf("Unexpected response: %s", cmp.Diff(got, expect))
+	}
+}
+
+func TestApplicationCredentials_CreateNode(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/application/nodes"
+		if expectURL != url {
+			t.Errorf("Request url does not match expected: %s", url)
+		}
+
+		expectBody := `{"name":"Test","location_id":1,"public":true,"fqdn":"pterodactyl.test","scheme":"https",` +
+			`"behind_proxy":false,"maintenance_mode":false,"memory":2048,"memory_overallocate":-1,"disk":5000,` +
+			`"disk_overallocate":0,"upload_size":100,"daemon_listen":8080,"daemon_sftp":2022,` +
+			`"daemon_base":"/srv/daemon-data"}`
+		if expectBody != string(data) {
+			t.Errorf("Request data does not match expected: %s", string(data))
+		}
+
+		res := `{
+		  "object": "node",
+		  "attributes": {
+			"id": 1,
+			"uuid": "1046d1d1-b8ef-4771-82b1-2b5946d33397",
+			"public": true,
+			"name": "Test",
+			"description": "",
+			"location_id": 1,
+			"fqdn": "pterodactyl.test",
+			"scheme": "https",
+			"behind_proxy": false,
+			"maintenance_mode": false,
+			"memory": 2048,
+			"memory_overallocate": -1,
+			"disk": 5000,
+			"disk_overallocate": 0,
+			"upload_size": 100,
+			"daemon_listen": 8080,
+			"daemon_sftp": 2022,
+			"daemon_base": "/srv/daemon-data",
+			"created_at": "2019-12-22T04:44:51+00:00",
+			"updated_at": "2019-12-22T04:44:51+00:00",
+			"relationships": {
+			  "location": {
+				"object": "location",
+				"attributes": {
+				  "id": 1,
+				  "short": "test",
+				  "long": "Test Location",
+				  "updated_at": "2019-12-22T04:44:51+00:00",
+				  "created_at": "2019-12-22T04:44:51+00:00"
+				}
+			  }
+			}
+		  }
+		}`
+
+		return []byte(res), nil
+	}
+
+	a := NewApplication("https://example.com", "")
+
+	d, _ := time.Parse(time.RFC3339, "2019-12-22T04:44:51+00:00")
+
+	n := &Node{
+		ID:                 1,
+		UUID:               "1046d1d1-b8ef-4771-82b1-2b5946d33397",
+		Public:             true,
+		Name:               "Test",
+		LocationID:         1,
+		FQDN:               "pterodactyl.test",
+		Scheme:             "https",
+		Memory:             2048,
+		MemoryOverallocate: -1,
+		Disk:               5000,
+		UploadSize:         100,
+		DaemonListen:       8080,
+		DaemonSFTP:         2022,
+		DaemonBase:         "/srv/daemon-data",
+		Location: &Location{
+			ID:        1,
+			ShortName: "test",
+			LongName:  "Test Location",
+			UpdatedAt: d,
+			CreatedAt: d,
+		},
+		CreatedAt: d,
+		UpdatedAt: d,
+	}
+
+	got, err := a.CreateNode(n)
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	expect := &Node{
+		ID:                 1,
+		UUID:               "1046d1d1-b8ef-4771-82b1-2b5946d33397",
+		Public:             true,
+		Name:               "Test",
+		LocationID:         1,
+		FQDN:               "pterodactyl.test",
+		Scheme:             "https",
+		Memory:             2048,
+		MemoryOverallocate: -1,
+		Disk:               5000,
+		UploadSize:         100,
+		DaemonListen:       8080,
+		DaemonSFTP:         2022,
+		DaemonBase:         "/srv/daemon-data",
+		Location: &Location{
+			ID:        1,
+			ShortName: "test",
+			LongName:  "Test Location",
+			UpdatedAt: d,
+			CreatedAt: d,
+		},
+		CreatedAt: d,
+		UpdatedAt: d,
+	}
+
+	if !cmp.Equal(got, expect) {
+		t.Errorf("Unexpected response: %s", cmp.Diff(got, expect))
+	}
+}
+
+func TestApplicationCredentials_UpdateNode(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/application/nodes/1"
+		if expectURL != url || method != "PATCH" {
+			t.Errorf("Request does not match expected: %s %s", method, url)
+		}
+
+		res := `{
+		  "object": "node",
+		  "attributes": {
+			"id": 1,
+			"uuid": "1046d1d1-b8ef-4771-82b1-2b5946d33397",
+			"public": true,
+			"name": "Test",
+			"description": "",
+			"location_id": 1,
+			"fqdn": "pterodactyl.test",
+			"scheme": "https",
+			"behind_proxy": false,
+			"maintenance_mode": false,
+			"memory": 2048,
+			"memory_overallocate": -1,
+			"disk": 5000,
+			"disk_overallocate": 0,
+			"upload_size": 100,
+			"daemon_listen": 8080,
+			"daemon_sftp": 2022,
+			"daemon_base": "/srv/daemon-data",
+			"created_at": "2019-12-22T04:44:51+00:00",
+			"updated_at": "2019-12-22T04:44:51+00:00",
+			"relationships": {
+			  "location": {
+				"object": "location",
+				"attributes": {
+				  "id": 1,
+				  "short": "test",
+				  "long": "Test Location",
+				  "updated_at": "2019-12-22T04:44:51+00:00",
+				  "created_at": "2019-12-22T04:44:51+00:00"
+				}
+			  }
+			}
+		  }
+		}`
+
+		return []byte(res), nil
+	}
+
+	a := NewApplication("https://example.com", "")
+
+	d, _ := time.Parse(time.RFC3339, "2019-12-22T04:44:51+00:00")
+
+	n := &Node{
+		ID:                 1,
+		UUID:               "1046d1d1-b8ef-4771-82b1-2b5946d33397",
+		Public:             true,
+		Name:               "Test",
+		LocationID:         1,
+		FQDN:               "pterodactyl.test",
+		Scheme:             "https",
+		Memory:             2048,
+		MemoryOverallocate: -1,
+		Disk:               5000,
+		UploadSize:         100,
+		DaemonListen:       8080,
+		DaemonSFTP:         2022,
+		DaemonBase:         "/srv/daemon-data",
+		Location: &Location{
+			ID:        1,
+			ShortName: "test",
+			LongName:  "Test Location",
+			UpdatedAt: d,
+			CreatedAt: d,
+		},
+		CreatedAt: d,
+		UpdatedAt: d,
+	}
+
+	n.MaintenanceMode = true
+
+	err := a.UpdateNode(n)
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+}
+
+func TestApplicationCredentials_DeleteNode(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/application/nodes/1"
+		if expectURL != url || method != "DELETE" {
+			t.Errorf("Request does not match expected: %s %s", method, url)
+		}
+
+		return nil, nil
+	}
+
+	a := NewApplication("https://example.com", "")
+
+	err := a.DeleteNode(1)
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+}
+
+func TestApplicationCredentials_GetNodeConfiguration(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/application/nodes/1/configuration"
+		if expectURL != url {
+			t.Errorf("Request url does not match expected: %s", url)
+		}
+
+		res := `{
+		  "debug": false,
+		  "uuid": "1046d1d1-b8ef-4771-82b1-2b5946d33397",
+		  "token_id": "mB8vXc0cbJjHXlFm",
+		  "token": "vl6oHkm1mnvRG8P0gs2ns2xNmTtb4Tt6DHEEqmRyrpaKzQ3SNAaj2HsNXtD2qzQG",
+		  "api": {
+			"host": "0.0.0.0",
+			"port": 8080,
+			"ssl": {
+			  "enabled": true,
+			  "cert": "/etc/letsencrypt/live/node.example.com/fullchain.pem",
+			  "key": "/etc/letsencrypt/live/node.example.com/privkey.pem"
+			},
+			"upload_limit": 100
+		  },
+		  "system": {
+			"data": "/var/lib/pterodactyl/volumes",
+			"sftp": {
+			  "bind_port": 2022
+			}
+		  },
+		  "allowed_mounts": [],
+		  "remote": "https://example.com"
+		}`
+
+		return []byte(res), nil
+	}
+
+	a := NewApplication("https://example.com", "")
+
+	got, err := a.GetNodeConfiguration(1)
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	if got.TokenID != "mB8vXc0cbJjHXlFm" || got.API.Port != 8080 || got.System.SFTP.BindPort != 2022 {
+		t.Errorf("Unexpected response: %+v", got)
+	}
+
+	yml, err := got.YAML()
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	expect := `debug: false
+uuid: 1046d1d1-b8ef-4771-82b1-2b5946d33397
+token_id: mB8vXc0cbJjHXlFm
+token: vl6oHkm1mnvRG8P0gs2ns2xNmTtb4Tt6DHEEqmRyrpaKzQ3SNAaj2HsNXtD2qzQG
+api:
+  host: 0.0.0.0
+  port: 8080
+  ssl:
+    enabled: true
+    cert: /etc/letsencrypt/live/node.example.com/fullchain.pem
+    key: /etc/letsencrypt/live/node.example.com/privkey.pem
+  upload_limit: 100
+system:
+  data: /var/lib/pterodactyl/volumes
+  sftp:
+    bind_port: 2022
+allowed_mounts: []
+remote: https://example.com
+`
+
+	if string(yml) != expect {
+		t.Errorf("Unexpected YAML: %s", yml)
+	}
+}
+
+func TestApplicationCredentials_FindDeployableNodes(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/application/nodes/deployable?disk=2048&location_ids%5B%5D=1&location_ids%5B%5D=3&memory=1024"
+		if expectURL != url {
+			t.Errorf("Request url does not match expected: %s", url)
+		}
+
+		return []byte(`{"object":"list","data":[{
+		  "object": "node",
+		  "attributes": {
+			"id": 1,
+			"uuid": "1046d1d1-b8ef-4771-82b1-2b5946d33397",
+			"public": true,
+			"name": "Test",
+			"description": "",
+			"location_id": 1,
+			"fqdn": "pterodactyl.test",
+			"scheme": "https",
+			"behind_proxy": false,
+			"maintenance_mode": false,
+			"memory": 2048,
+			"memory_overallocate": -1,
+			"disk": 5000,
+			"disk_overallocate": 0,
+			"upload_size": 100,
+			"daemon_listen": 8080,
+			"daemon_sftp": 2022,
+			"daemon_base": "/srv/daemon-data",
+			"created_at": "2019-12-22T04:44:51+00:00",
+			"updated_at": "2019-12-22T04:44:51+00:00",
+			"relationships": {
+			  "location": {
+				"object": "location",
+				"attributes": {
+				  "id": 1,
+				  "short": "test",
+				  "long": "Test Location",
+				  "updated_at": "2019-12-22T04:44:51+00:00",
+				  "created_at": "2019-12-22T04:44:51+00:00"
+				}
+			  }
+			}
+		  }
+		}]}`), nil
+	}
+
+	a := NewApplication("https://example.com", "")
+
+	got, err := a.FindDeployableNodes(1024, 2048, 1, 3)
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	if len(got) != 1 || got[0].ID != 1 {
+		t.Errorf("Unexpected response: %v", got)
+	}
+}
@@ -0,0 +1,185 @@
+package fossil
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+//***** Structures *****//
+
+// PageOptions sets where a listing starts and how many items are fetched on each request
+type PageOptions struct {
+	Page    int // Page to start at. The first page is used if not set.
+	PerPage int // Items per page. The panel default is used if not set.
+}
+
+// ListOptions filters, sorts and paginates the results of the list calls. Which filters and sort fields are
+// accepted depends on the endpoint, e.g. users can be filtered by email, uuid, username and external_id, and
+// servers by name, uuid and external_id.
+type ListOptions struct {
+	PageOptions
+	Filters map[string]string // Sent as filter[key]=value
+	Sort    string            // Field to sort by. Prefix it with "-" for descending order.
+	Include []string          // Relationships to include along with the results
+}
+
+// jsonPage is a generic page of any list endpoint. The attributes of each item are kept raw so they can be
+// decoded into the matching type.
+type jsonPage struct {
+	Data []struct {
+		Attributes json.RawMessage `json:"attributes"`
+	} `json:"data"`
+	Meta Meta `json:"meta"`
+}
+
+// pager lazily walks the pages of a list endpoint. It's embedded in the typed iterators, which decode the items.
+type pager struct {
+	ctx   context.Context
+	c     *Credentials
+	url   *url.URL // URL of the next page, nil when there are no more
+	page  *jsonPage
+	index int
+	err   error
+}
+
+//***** Pagination *****//
+
+// newPager prepares a pager for the list at target. Nothing is fetched until the first item is requested.
+func newPager(ctx context.Context, c *Credentials, target string, opts *ListOptions) pager {
+	u, err := url.Parse(target)
+	if err != nil {
+		return pager{err: err}
+	}
+
+	if opts != nil {
+		u.RawQuery = opts.apply(u.Query()).Encode()
+	}
+
+	return pager{ctx: ctx, c: c, url: u}
+}
+
+// apply adds the options to the query parameters. Includes are merged with the ones already present.
+func (o *ListOptions) apply(q url.Values) url.Values {
+	if o.Page > 0 {
+		q.Set("page", strconv.Itoa(o.Page))
+	}
+
+	if o.PerPage > 0 {
+		q.Set("per_page", strconv.Itoa(o.PerPage))
+	}
+
+	for k, v := range o.Filters {
+		q.Set("filter["+k+"]", v)
+	}
+
+	if o.Sort != "" {
+		q.Set("sort", o.Sort)
+	}
+
+	if len(o.Include) > 0 {
+		var includes []string
+		if q.Get("include") != "" {
+			includes = strings.Split(q.Get("include"), ",")
+		}
+
+		for _, inc := range o.Include {
+			if !containsString(includes, inc) {
+				includes = append(includes, inc)
+			}
+		}
+
+		q.Set("include", strings.Join(includes, ","))
+	}
+
+	return q
+}
+
+// listOptions returns the first of the optional list options, or nil if none were given
+func listOptions(opts []*ListOptions) *ListOptions {
+	if len(opts) > 0 {
+		return opts[0]
+	}
+
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// next advances to the next item, fetching a new page when the current one is exhausted, and decodes it into v
+func (p *pager) next(v interface{}) bool {
+	if p.err != nil {
+		return false
+	}
+
+	for p.page == nil || p.index >= len(p.page.Data) {
+		if p.url == nil {
+			return false
+		}
+
+		p.err = p.fetch()
+		if p.err != nil {
+			return false
+		}
+	}
+
+	p.err = json.Unmarshal(p.page.Data[p.index].Attributes, v)
+	p.index++
+
+	return p.err == nil
+}
+
+// fetch loads the page at p.url and prepares the URL of the following one
+func (p *pager) fetch() error {
+	bytes, err := query(p.ctx, p.c, p.url.String(), "GET", nil)
+	if err != nil {
+		return err
+	}
+
+	var page jsonPage
+	err = json.Unmarshal(bytes, &page)
+	if err != nil {
+		return err
+	}
+
+	p.page = &page
+	p.index = 0
+
+	// The next page is built from the current URL rather than the links given by the panel, since those drop
+	// parameters like the includes
+	pg := page.Meta.Pagination
+	if pg.CurrentPage < 1 || pg.CurrentPage >= pg.TotalPages {
+		p.url = nil
+		return nil
+	}
+
+	q := p.url.Query()
+	q.Set("page", strconv.Itoa(pg.CurrentPage+1))
+	p.url.RawQuery = q.Encode()
+
+	return nil
+}
+
+// Err returns the error that stopped the iteration, if any
+func (p *pager) Err() error {
+	return p.err
+}
+
+// Total returns the total amount of items reported by the panel. It's only known after the first call to Next.
+func (p *pager) Total() int {
+	if p.page == nil {
+		return 0
+	}
+
+	return p.page.Meta.Pagination.Total
+}
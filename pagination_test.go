@@ -0,0 +1,150 @@
+package fossil
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+//***** Testing *****//
+
+// pagedResponse builds a page holding a single server with the page number as ID
+func pagedResponse(page, total int) []byte {
+	return []byte(fmt.Sprintf(`{
+	  "object": "list",
+	  "data": [
+		{
+		  "object": "server",
+		  "attributes": {
+			"id": %d
+		  }
+		}
+	  ],
+	  "meta": {
+		"pagination": {
+		  "total": %d,
+		  "count": 1,
+		  "per_page": 1,
+		  "current_page": %d,
+		  "total_pages": %d,
+		  "links": {}
+		}
+	  }
+	}`, page, total, page, total))
+}
+
+func TestApplicationCredentials_ServersIter(t *testing.T) {
+	var urls []string
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		urls = append(urls, url)
+		return pagedResponse(len(urls)+1, 4), nil
+	}
+
+	a := NewApplication("https://example.com", "")
+
+	it := a.ServersIter(&ListOptions{PageOptions: PageOptions{Page: 2, PerPage: 1}})
+
+	var ids []int
+	for it.Next() {
+		ids = append(ids, it.Value().ID)
+	}
+
+	if it.Err() != nil {
+		t.Errorf("Error: %s", it.Err().Error())
+	}
+
+	if fmt.Sprint(ids) != "[2 3 4]" {
+		t.Errorf("Unexpected servers: %v", ids)
+	}
+
+	if it.Total() != 4 {
+		t.Errorf("Unexpected total: %d", it.Total())
+	}
+
+	expect := []string{
+		"https://example.com/api/application/servers?include=allocations&page=2&per_page=1",
+		"https://example.com/api/application/servers?include=allocations&page=3&per_page=1",
+		"https://example.com/api/application/servers?include=allocations&page=4&per_page=1",
+	}
+
+	if fmt.Sprint(urls) != fmt.Sprint(expect) {
+		t.Errorf("Request urls do not match expected: %v", urls)
+	}
+}
+
+func TestApplicationCredentials_ServersIterEarlyStop(t *testing.T) {
+	calls := 0
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		calls++
+		return pagedResponse(calls, 10), nil
+	}
+
+	a := NewApplication("https://example.com", "")
+
+	it := a.ServersIter(nil)
+	for it.Next() {
+		if it.Value().ID == 2 {
+			break
+		}
+	}
+
+	if calls != 2 {
+		t.Errorf("Expected 2 pages to be fetched, got %d", calls)
+	}
+}
+
+func TestApplicationCredentials_ServersIterError(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		return nil, &APIError{StatusCode: 500}
+	}
+
+	a := NewApplication("https://example.com", "")
+
+	it := a.ServersIter(nil)
+	if it.Next() {
+		t.Error("Expected the iteration to stop")
+	}
+
+	if it.Err() == nil {
+		t.Error("Expected an error")
+	}
+}
+
+func TestApplicationCredentials_GetUsersFiltered(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/application/users?filter%5Bemail%5D=example%40example.com&sort=-id"
+		if expectURL != url {
+			t.Errorf("Request url does not match expected: %s", url)
+		}
+
+		return []byte(`{"object":"list","data":[]}`), nil
+	}
+
+	a := NewApplication("https://example.com", "")
+
+	_, err := a.GetUsers(&ListOptions{
+		Filters: map[string]string{"email": "example@example.com"},
+		Sort:    "-id",
+	})
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+}
+
+func TestListOptions_Include(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/application/servers?include=allocations%2Cuser"
+		if expectURL != url {
+			t.Errorf("Request url does not match expected: %s", url)
+		}
+
+		return []byte(`{"object":"list","data":[]}`), nil
+	}
+
+	a := NewApplication("https://example.com", "")
+
+	_, err := a.GetServers(&ListOptions{Include: []string{"allocations", "user"}})
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+}
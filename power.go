@@ -0,0 +1,256 @@
+package fossil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Using a variable allows faster polling while testing
+var statePollInterval = 2 * time.Second
+
+//***** Structures *****//
+
+// StateTimeoutError is returned when a server doesn't reach the expected state before the context is done
+type StateTimeoutError struct {
+	ServerID string
+	Expected ServerState
+	LastSeen ServerState // Empty if the state was never fetched
+	Err      error       // The error of the context
+}
+
+//***** Errors *****//
+
+func (e *StateTimeoutError) Error() string {
+	return fmt.Sprintf("server %s did not reach the %s state (last seen: %s): %s", e.ServerID, e.Expected,
+		e.LastSeen, e.Err)
+}
+
+// Unwrap returns the error of the context, so it can be matched with errors.Is
+func (e *StateTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+//***** Helpers *****//
+
+// normalizeState maps the states given by older panels ("on" and "off") to the current ones
+func normalizeState(state ServerState) ServerState {
+	switch state {
+	case "on":
+		return StateRunning
+	case "off":
+		return StateOffline
+	}
+
+	return state
+}
+
+// targetState returns the state a server ends in after a power signal
+func targetState(signal PowerSignal) (ServerState, error) {
+	switch signal {
+	case ON, RESTART:
+		return StateRunning, nil
+	case OFF, KILL:
+		return StateOffline, nil
+	}
+
+	return "", errors.New("unknown power signal: " + string(signal))
+}
+
+//***** Requests *****//
+
+// WaitForState polls the status of the server until it reaches the given state, usually StateRunning or
+// StateOffline. If the context is done first a *StateTimeoutError is returned.
+func (c *ClientCredentials) WaitForState(ctx context.Context, id string, state ServerState) error {
+	var last ServerState
+	for {
+		ss, err := c.GetServerStatusContext(ctx, id)
+		if err != nil {
+			if ctx.Err() != nil {
+				return &StateTimeoutError{ServerID: id, Expected: state, LastSeen: last, Err: ctx.Err()}
+			}
+
+			return err
+		}
+
+		last = ss.State
+		if last == state {
+			return nil
+		}
+
+		err = sleep(ctx, statePollInterval)
+		if err != nil {
+			return &StateTimeoutError{ServerID: id, Expected: state, LastSeen: last, Err: err}
+		}
+	}
+}
+
+// SetPowerStateAndWait sends a power signal to the server and waits until it's running, for ON and RESTART, or
+// offline, for OFF and KILL. For a RESTART it first waits for the server to go down, which is seen either as a
+// state other than running or, on newer panels, as its uptime starting over.
+func (c *ClientCredentials) SetPowerStateAndWait(ctx context.Context, id string, signal PowerSignal) error {
+	state, err := targetState(signal)
+	if err != nil {
+		return err
+	}
+
+	// The uptime before the signal tells a restarted server apart from one that didn't stop yet
+	var before *ServerStatus
+	if signal == RESTART {
+		before, err = c.GetServerStatusContext(ctx, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = c.SetPowerStateContext(ctx, id, signal)
+	if err != nil {
+		return err
+	}
+
+	if before != nil && before.State == StateRunning {
+		err = c.waitForRestart(ctx, id, before.Uptime)
+		if err != nil {
+			return err
+		}
+	}
+
+	return c.WaitForState(ctx, id, state)
+}
+
+// waitForRestart polls the status of the server until it leaves the running state or its uptime is reset
+func (c *ClientCredentials) waitForRestart(ctx context.Context, id string, uptime time.Duration) error {
+	for {
+		ss, err := c.GetServerStatusContext(ctx, id)
+		if err != nil {
+			if ctx.Err() != nil {
+				return &StateTimeoutError{ServerID: id, Expected: StateRunning, LastSeen: StateRunning,
+					Err: ctx.Err()}
+			}
+
+			return err
+		}
+
+		// Older panels don't give the uptime, so only the state can be relied on
+		if ss.State != StateRunning || (uptime > 0 && ss.Uptime < uptime) {
+			return nil
+		}
+
+		err = sleep(ctx, statePollInterval)
+		if err != nil {
+			return &StateTimeoutError{ServerID: id, Expected: StateRunning, LastSeen: ss.State, Err: err}
+		}
+	}
+}
+
+//***** Console *****//
+
+// WaitForState waits until the server reaches the given state, returning right away if it's the last state
+// received. The Status channel is left untouched. If the context is done first a *StateTimeoutError is returned.
+func (cn *Console) WaitForState(ctx context.Context, state ServerState) error {
+	w, current := cn.watch()
+	defer cn.unwatch(w)
+
+	if normalizeState(current) == state {
+		return nil
+	}
+
+	// Daemon errors are unrelated to the wait when no signal was sent
+	return cn.waitFor(ctx, w.states, nil, state)
+}
+
+// SetPowerStateAndWait sends a power signal through the console and waits until the server is running, for ON
+// and RESTART, or offline, for OFF and KILL. It returns right away if the server is already in that state, except
+// for a RESTART, and ends with a *DaemonError if the daemon reports one after the signal. Only the states
+// received after the signal count, and unlike polling the console sees the stop of a restart.
+func (cn *Console) SetPowerStateAndWait(ctx context.Context, signal PowerSignal) error {
+	state, err := targetState(signal)
+	if err != nil {
+		return err
+	}
+
+	w, current := cn.watch()
+	defer cn.unwatch(w)
+
+	err = cn.SetPowerState(signal)
+	if err != nil {
+		return err
+	}
+
+	// The daemon sends no status when the server is already in the target state
+	if signal != RESTART && normalizeState(current) == state {
+		return nil
+	}
+
+	// A restart is only done once the server is back up after stopping
+	if signal == RESTART {
+		err = cn.waitForChange(ctx, w)
+		if err != nil {
+			return err
+		}
+	}
+
+	return cn.waitFor(ctx, w.states, w.errs, state)
+}
+
+// waitFor reads the watched states until the server reaches the given state. A nil errs channel is never read.
+func (cn *Console) waitFor(ctx context.Context, states <-chan ServerState, errs <-chan error,
+	state ServerState) error {
+	var last ServerState
+	for {
+		select {
+		case s, ok := <-states:
+			if !ok {
+				return cn.closedError(state)
+			}
+
+			last = normalizeState(s)
+			if last == state {
+				return nil
+			}
+		case err, ok := <-errs:
+			if !ok {
+				return cn.closedError(state)
+			}
+
+			return err
+		case <-ctx.Done():
+			return &StateTimeoutError{ServerID: cn.id, Expected: state, LastSeen: last, Err: ctx.Err()}
+		}
+	}
+}
+
+// waitForChange reads the watched states until the server leaves the running state
+func (cn *Console) waitForChange(ctx context.Context, w *stateWatch) error {
+	for {
+		select {
+		case s, ok := <-w.states:
+			if !ok {
+				return cn.closedError(StateRunning)
+			}
+
+			if normalizeState(s) != StateRunning {
+				return nil
+			}
+		case err, ok := <-w.errs:
+			if !ok {
+				return cn.closedError(StateRunning)
+			}
+
+			return err
+		case <-ctx.Done():
+			return &StateTimeoutError{ServerID: cn.id, Expected: StateRunning, LastSeen: StateRunning,
+				Err: ctx.Err()}
+		}
+	}
+}
+
+// closedError describes why the console closed while waiting for a state
+func (cn *Console) closedError(state ServerState) error {
+	if cn.Err() != nil {
+		return cn.Err()
+	}
+
+	return errors.New("console closed while waiting for the " + string(state) + " state")
+}
@@ -0,0 +1,333 @@
+package fossil
+
+import (
+	"context"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"github.com/gorilla/websocket"
+	"testing"
+	"time"
+)
+
+//***** Testing *****//
+
+func TestClientCredentials_WaitForState(t *testing.T) {
+	statePollInterval = time.Millisecond
+
+	states := []string{"starting", "starting", "running"}
+	var polls int
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/resources"
+		if expectURL != url {
+			t.Errorf("Request url does not match expected: %s", url)
+		}
+
+		state := states[polls]
+		if polls < len(states)-1 {
+			polls++
+		}
+
+		return []byte(`{"object":"stats","attributes":{"current_state":"` + state + `"}}`), nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	err := c.WaitForState(context.Background(), "1a7ce997", StateRunning)
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	if polls != 2 {
+		t.Errorf("Unexpected amount of polls: %d", polls)
+	}
+}
+
+func TestClientCredentials_WaitForState_Timeout(t *testing.T) {
+	statePollInterval = time.Millisecond
+
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		return []byte(`{"object":"stats","attributes":{"current_state":"stopping"}}`), nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := c.WaitForState(ctx, "1a7ce997", StateOffline)
+
+	var stErr *StateTimeoutError
+	if !errors.As(err, &stErr) {
+		t.Fatalf("Expected a StateTimeoutError, got: %v", err)
+	}
+
+	if stErr.LastSeen != StateStopping || stErr.Expected != StateOffline {
+		t.Errorf("Unexpected error: %s", stErr.Error())
+	}
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("The error does not wrap the context error")
+	}
+}
+
+func TestClientCredentials_SetPowerStateAndWait(t *testing.T) {
+	statePollInterval = time.Millisecond
+
+	var signaled bool
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		switch url {
+		case "https://example.com/api/client/servers/1a7ce997/power":
+			if string(data) != `{"signal":"stop"}` {
+				t.Errorf("Request body does not match expected: %s", data)
+			}
+
+			signaled = true
+			return nil, nil
+		case "https://example.com/api/client/servers/1a7ce997/resources":
+			return nil, &APIError{StatusCode: 404}
+		case "https://example.com/api/client/servers/1a7ce997/utilization":
+			if !signaled {
+				t.Error("The status was polled before sending the signal")
+			}
+
+			// Older panels report the states as on and off
+			return []byte(`{"object":"stats","attributes":{"state":"off"}}`), nil
+		}
+
+		t.Errorf("Unexpected request url: %s", url)
+		return nil, nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	err := c.SetPowerStateAndWait(context.Background(), "1a7ce997", OFF)
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	err = c.SetPowerStateAndWait(context.Background(), "1a7ce997", "reboot")
+	if err == nil {
+		t.Error("Expected an error for an unknown signal")
+	}
+}
+
+func TestClientCredentials_SetPowerStateAndWait_Restart(t *testing.T) {
+	statePollInterval = time.Millisecond
+
+	// The server is still seen running after the signal, first with the old uptime and then with a new one
+	statuses := []string{
+		`"current_state":"running","resources":{"uptime":60000}`,
+		`"current_state":"running","resources":{"uptime":61000}`,
+		`"current_state":"running","resources":{"uptime":500}`,
+	}
+
+	var signaled bool
+	var polls int
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		switch url {
+		case "https://example.com/api/client/servers/1a7ce997/power":
+			if string(data) != `{"signal":"restart"}` {
+				t.Errorf("Request body does not match expected: %s", data)
+			}
+
+			if polls != 1 {
+				t.Errorf("The signal was sent after %d polls", polls)
+			}
+
+			signaled = true
+			return nil, nil
+		case "https://example.com/api/client/servers/1a7ce997/resources":
+			if signaled && polls == 0 {
+				t.Error("The uptime was not fetched before sending the signal")
+			}
+
+			res := statuses[polls]
+			if polls < len(statuses)-1 {
+				polls++
+			}
+
+			return []byte(`{"object":"stats","attributes":{` + res + `}}`), nil
+		}
+
+		t.Errorf("Unexpected request url: %s", url)
+		return nil, nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	err := c.SetPowerStateAndWait(context.Background(), "1a7ce997", RESTART)
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	if polls != len(statuses)-1 {
+		t.Errorf("The wait ended before the uptime was reset, after %d polls", polls)
+	}
+}
+
+func TestConsole_SetPowerStateAndWait(t *testing.T) {
+	srv := wingsStub(t, func(conn *websocket.Conn) {
+		_ = conn.WriteJSON(consoleMessage{Event: eventStatus, Args: []string{string(StateRunning)}})
+
+		var m consoleMessage
+		err := conn.ReadJSON(&m)
+		if err != nil || m.Event != eventSetState || m.Args[0] != string(RESTART) {
+			t.Errorf("Unexpected power message: %+v", m)
+			return
+		}
+
+		for _, s := range []ServerState{StateStopping, StateOffline, StateStarting, StateRunning} {
+			_ = conn.WriteJSON(consoleMessage{Event: eventStatus, Args: []string{string(s)}})
+		}
+
+		_ = conn.ReadJSON(&m) // Wait for the close
+	})
+	defer srv.Close()
+
+	stubWebsocketToken(t, srv, "token-1")
+
+	c := NewClient("https://example.com", "")
+
+	cn, err := c.Console("1a7ce997")
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+	defer cn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err = cn.WaitForState(ctx, StateRunning)
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	err = cn.SetPowerStateAndWait(ctx, RESTART)
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	// The waits don't take the states from the Status channel
+	var got []ServerState
+	for len(got) < 5 {
+		got = append(got, <-cn.Status)
+	}
+
+	expect := []ServerState{StateRunning, StateStopping, StateOffline, StateStarting, StateRunning}
+	if !cmp.Equal(got, expect) {
+		t.Errorf("Unexpected response: %s", cmp.Diff(got, expect))
+	}
+}
+
+func TestConsole_SetPowerStateAndWait_StaleStates(t *testing.T) {
+	release := make(chan struct{})
+	srv := wingsStub(t, func(conn *websocket.Conn) {
+		// States from before the wait, left unread in the Status channel
+		for _, s := range []ServerState{StateRunning, StateStopping, StateOffline} {
+			_ = conn.WriteJSON(consoleMessage{Event: eventStatus, Args: []string{string(s)}})
+		}
+
+		var m consoleMessage
+		err := conn.ReadJSON(&m)
+		if err != nil || m.Event != eventSetState || m.Args[0] != string(ON) {
+			t.Errorf("Unexpected power message: %+v", m)
+			return
+		}
+
+		_ = conn.WriteJSON(consoleMessage{Event: eventStatus, Args: []string{string(StateStarting)}})
+		<-release
+		_ = conn.WriteJSON(consoleMessage{Event: eventStatus, Args: []string{string(StateRunning)}})
+
+		_ = conn.ReadJSON(&m) // Wait for the close
+	})
+	defer srv.Close()
+
+	stubWebsocketToken(t, srv, "token-1")
+
+	c := NewClient("https://example.com", "")
+
+	cn, err := c.Console("1a7ce997")
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+	defer cn.Close()
+
+	for len(cn.Status) < 3 {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result := make(chan error, 1)
+	go func() {
+		result <- cn.SetPowerStateAndWait(ctx, ON)
+	}()
+
+	select {
+	case err = <-result:
+		t.Fatalf("The wait ended before the server started: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+
+	err = <-result
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+}
+
+func TestConsole_SetPowerStateAndWait_AlreadyInState(t *testing.T) {
+	srv := wingsStub(t, func(conn *websocket.Conn) {
+		_ = conn.WriteJSON(consoleMessage{Event: eventStatus, Args: []string{string(StateRunning)}})
+
+		// No status is sent for a server that's already running
+		var m consoleMessage
+		err := conn.ReadJSON(&m)
+		if err != nil || m.Event != eventSetState || m.Args[0] != string(ON) {
+			t.Errorf("Unexpected power message: %+v", m)
+			return
+		}
+
+		err = conn.ReadJSON(&m)
+		if err != nil || m.Event != eventSetState || m.Args[0] != string(RESTART) {
+			t.Errorf("Unexpected power message: %+v", m)
+			return
+		}
+
+		_ = conn.WriteJSON(consoleMessage{Event: eventDaemonError, Args: []string{"server is busy"}})
+
+		_ = conn.ReadJSON(&m) // Wait for the close
+	})
+	defer srv.Close()
+
+	stubWebsocketToken(t, srv, "token-1")
+
+	c := NewClient("https://example.com", "")
+
+	cn, err := c.Console("1a7ce997")
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+	defer cn.Close()
+
+	for len(cn.Status) < 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err = cn.SetPowerStateAndWait(ctx, ON)
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	// The daemon refusing the signal ends the wait
+	err = cn.SetPowerStateAndWait(ctx, RESTART)
+	var dErr *DaemonError
+	if !errors.As(err, &dErr) || dErr.Message != "server is busy" {
+		t.Errorf("Expected a daemon error, got: %v", err)
+	}
+}
@@ -0,0 +1,154 @@
+package fossil
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+//***** Structures *****//
+
+// RateLimiter is a token bucket limiting the requests made to a panel. It's safe for concurrent use, and a single
+// limiter can be shared between several credentials that point to the same panel.
+//
+// The limiter adapts to the X-RateLimit-Remaining and X-RateLimit-Reset headers sent by the panel, so requests
+// made by other clients with the same token are taken into account.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // Tokens added per second
+	burst  float64
+	tokens float64
+	last   time.Time
+	paused time.Time // No tokens are given until this time
+}
+
+// NewRateLimiter creates a limiter allowing perMinute requests every minute, with bursts of up to burst requests.
+// A burst lower than 1 is treated as 1. A perMinute of 0 or less sets no limit, and the requests are only held
+// back while the panel asks to wait.
+func NewRateLimiter(perMinute, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &RateLimiter{
+		rate:   float64(perMinute) / 60,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+//***** Options *****//
+
+// WithRateLimit limits the credentials to perMinute requests every minute. Pterodactyl throttles the API to 240
+// requests per minute by default. A perMinute of 0 or less only honors the waits asked by the panel.
+func WithRateLimit(perMinute int) Option {
+	return WithRateLimiter(NewRateLimiter(perMinute, 1))
+}
+
+// WithRateLimiter makes the credentials take their requests from the given limiter, allowing it to be shared
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(cfg *config) {
+		cfg.limiter = limiter
+	}
+}
+
+//***** Limiting *****//
+
+// Wait blocks until a request is allowed or the context is done. A nil limiter never blocks.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	d := l.reserve()
+	if d <= 0 {
+		return nil
+	}
+
+	err := sleep(ctx, d)
+	if err != nil {
+		// The request won't be made, give the token back
+		l.mu.Lock()
+		l.tokens = minFloat(l.tokens+1, l.burst)
+		l.mu.Unlock()
+	}
+
+	return err
+}
+
+// reserve takes a token and returns how long to wait before it can be used
+func (l *RateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.refill(now)
+	l.tokens--
+
+	var d time.Duration
+	if l.tokens < 0 {
+		if l.rate <= 0 {
+			// Without a rate there's no limit, only the pause set by the panel applies
+			l.tokens = 0
+		} else {
+			d = time.Duration(-l.tokens / l.rate * float64(time.Second))
+		}
+	}
+
+	if l.paused.After(now) && l.paused.Sub(now) > d {
+		d = l.paused.Sub(now)
+	}
+
+	return d
+}
+
+// refill adds the tokens generated since the last refill
+func (l *RateLimiter) refill(now time.Time) {
+	elapsed := now.Sub(l.last).Seconds()
+	if elapsed > 0 {
+		l.tokens = minFloat(l.tokens+elapsed*l.rate, l.burst)
+		l.last = now
+	}
+}
+
+// observe adapts the limiter to the rate limit headers of a response
+func (l *RateLimiter) observe(rp *http.Response) {
+	if l == nil || rp == nil {
+		return
+	}
+
+	remaining, err := strconv.Atoi(rp.Header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.refill(now)
+
+	// The panel knows better how many requests are left
+	if float64(remaining) < l.tokens {
+		l.tokens = float64(remaining)
+	}
+
+	if remaining > 0 {
+		return
+	}
+
+	if d, ok := retryAfter(rp); ok {
+		l.paused = now.Add(d)
+	}
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+
+	return b
+}
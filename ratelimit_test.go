@@ -0,0 +1,90 @@
+package fossil
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+//***** Testing *****//
+
+func TestRateLimiter_Wait(t *testing.T) {
+	// 1200 per minute is a token every 50ms
+	l := NewRateLimiter(1200, 2)
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		err := l.Wait(context.Background())
+		if err != nil {
+			t.Fatalf("Error: %s", err.Error())
+		}
+	}
+
+	// Two requests come from the burst, the remaining two need a token each
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("Requests were not limited, took %s", elapsed)
+	}
+}
+
+func TestRateLimiter_WaitCanceled(t *testing.T) {
+	l := NewRateLimiter(1, 1)
+	_ = l.Wait(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := l.Wait(ctx)
+	if err != context.DeadlineExceeded {
+		t.Errorf("Expected the deadline to be exceeded, got: %v", err)
+	}
+}
+
+func TestRateLimiter_WaitNoRate(t *testing.T) {
+	l := NewRateLimiter(0, 1)
+
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		err := l.Wait(context.Background())
+		if err != nil {
+			t.Fatalf("Error: %s", err.Error())
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("Requests were limited, took %s", elapsed)
+	}
+
+	// The panel can still hold the requests back
+	l.observe(&http.Response{Header: http.Header{
+		"X-Ratelimit-Remaining": []string{"0"},
+		"Retry-After":           []string{"1"},
+	}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := l.Wait(ctx)
+	if err != context.DeadlineExceeded {
+		t.Errorf("Expected the deadline to be exceeded, got: %v", err)
+	}
+}
+
+func TestRateLimiter_Observe(t *testing.T) {
+	l := NewRateLimiter(6000, 10)
+
+	rp := &http.Response{Header: http.Header{}}
+	rp.Header.Set("X-RateLimit-Remaining", "0")
+	rp.Header.Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+	l.observe(rp)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// The panel reported no requests left until the reset, so the limiter must hold
+	err := l.Wait(ctx)
+	if err != context.DeadlineExceeded {
+		t.Errorf("Expected the deadline to be exceeded, got: %v", err)
+	}
+}
@@ -2,8 +2,7 @@ package fossil
 
 import (
 	"bytes"
-	"encoding/json"
-	"errors"
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -31,93 +30,91 @@ var query = queryURL
 
 //***** Queries *****//
 
-func (c *ApplicationCredentials) query(endpoint, method string, data []byte) ([]byte, error) {
-	target := fmt.Sprintf("%s/api/application/%s", c.URL, endpoint)
-	return query(target, c.Token, method, data)
+func (c *ApplicationCredentials) query(ctx context.Context, endpoint, method string, data []byte) ([]byte, error) {
+	return query(ctx, (*Credentials)(c), c.endpointURL(endpoint), method, data)
 }
 
-func (c *ClientCredentials) query(endpoint, method string, data []byte) ([]byte, error) {
-	target := fmt.Sprintf("%s/api/client/%s", c.URL, endpoint)
-	return query(target, c.Token, method, data)
+func (c *ClientCredentials) query(ctx context.Context, endpoint, method string, data []byte) ([]byte, error) {
+	return query(ctx, (*Credentials)(c), c.endpointURL(endpoint), method, data)
 }
 
-func queryURL(url, token, method string, data []byte) ([]byte, error) {
-	client := &http.Client{}
-	rq, _ := http.NewRequest(method, url, bytes.NewBuffer(data))
+// endpointURL builds the full URL of an application API endpoint
+func (c *ApplicationCredentials) endpointURL(endpoint string) string {
+	return fmt.Sprintf("%s/api/application/%s", c.URL, endpoint)
+}
 
-	rq.Header.Set("Authorization", "Bearer "+token)
-	rq.Header.Set("Accept", "Application/vnd.pterodactyl.v1+json")
-	rq.Header.Set("Content-Type", "application/json")
+// endpointURL builds the full URL of a client API endpoint
+func (c *ClientCredentials) endpointURL(endpoint string) string {
+	return fmt.Sprintf("%s/api/client/%s", c.URL, endpoint)
+}
 
-	rp, err := client.Do(rq)
-	if err != nil {
-		return nil, err
-	}
+func queryURL(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+	for attempt := 1; ; attempt++ {
+		err := c.limiter.Wait(ctx)
+		if err != nil {
+			return nil, err
+		}
 
-	var body []byte
-	// Success status range
-	if rp.StatusCode < 200 || rp.StatusCode > 226 {
-		// Response was a not-success code
-		body, _ = ioutil.ReadAll(rp.Body)
+		body, rp, err := send(ctx, c, url, method, data)
+		c.limiter.observe(rp)
 
-		// No additional error info given
-		if body == nil {
-			return nil, errors.New("remote server responded with status " + rp.Status)
+		delay, retry := c.retry.next(attempt, method, rp, err)
+		if !retry || ctx.Err() != nil {
+			return body, err
 		}
 
-		rqErr, err := parseError(body)
-		// Info was there but unable to be decoded
-		if err != nil {
-			msg := fmt.Sprintf("remote server responded with status %s."+
-				" aditionaly another error occurred while decoding the error: %s", rp.Status, err.Error())
-			return nil, errors.New(msg)
+		if c.retry.OnRetry != nil {
+			c.retry.OnRetry(RetryInfo{
+				Attempt: attempt,
+				Method:  method,
+				URL:     url,
+				Delay:   delay,
+				Err:     err,
+			})
 		}
 
-		// Was able to parse the error details
-		msg := fmt.Sprintf("remote server responded with status %s (%s): %s",
-			rqErr.Status, rqErr.Code, rqErr.Detail)
-		return nil, errors.New(msg)
+		err = sleep(ctx, delay)
+		if err != nil {
+			return nil, err
+		}
 	}
+}
 
-	if rp.Body != nil {
-		body, _ = ioutil.ReadAll(rp.Body)
+// send performs a single request. The response is returned, even on error, so its headers can be inspected.
+func send(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, *http.Response, error) {
+	rq, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(data))
+	if err != nil {
+		return nil, nil, err
 	}
 
-	rp.Body = ioutil.NopCloser(bytes.NewBuffer(body))
-
-	err = rp.Body.Close()
-	if err != nil {
-		return body, errors.New("unable to close response body")
+	for k, v := range c.headers {
+		rq.Header[k] = v
 	}
 
-	return body, nil
-}
+	if c.userAgent != "" {
+		rq.Header.Set("User-Agent", c.userAgent)
+	}
 
-//***** Errors *****//
+	rq.Header.Set("Authorization", "Bearer "+c.Token)
+	rq.Header.Set("Accept", "Application/vnd.pterodactyl.v1+json")
+	rq.Header.Set("Content-Type", "application/json")
 
-// requestError contains error details for Pterodactyl requests errors
-type requestError struct {
-	Code   string `json:"code"` // For some reason the code is given as a string
-	Status string `json:"status"`
-	Detail string `json:"detail"`
-}
+	rp, err := c.client().Do(rq)
+	if err != nil {
+		return nil, nil, err
+	}
 
-// jsonRequestErrors allows the JSON provided to be decoded
-type jsonRequestErrors struct {
-	Errors []*requestError `json:"errors"`
-}
+	defer rp.Body.Close()
 
-// parseError takes a Pterodactyl-formatted error and parses it into a struct
-func parseError(bytes []byte) (*requestError, error) {
-	var rqErrors jsonRequestErrors
-	err := json.Unmarshal(bytes, &rqErrors)
+	body, err := ioutil.ReadAll(rp.Body)
 	if err != nil {
-		return nil, err
+		return nil, rp, err
 	}
 
-	if len(rqErrors.Errors) < 1 {
-		return nil, errors.New("no error details given")
+	// Success status range
+	if rp.StatusCode < 200 || rp.StatusCode > 226 {
+		return nil, rp, newAPIError(rp, body)
 	}
 
-	return rqErrors.Errors[0], nil
+	return body, rp, nil
 }
@@ -1,12 +1,16 @@
 package fossil
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 )
 
 func TestQueryURL(t *testing.T) {
-	res, err := queryURL("https://reqbin.com/echo/get/json", "", "GET", nil)
+	res, err := queryURL(context.Background(), &Credentials{}, "https://reqbin.com/echo/get/json", "GET", nil)
 	if err != nil {
 		t.Errorf("Error: %s", err.Error())
 	}
@@ -17,3 +21,42 @@ func TestQueryURL(t *testing.T) {
 		t.Errorf("Response data unexpected: %s", res)
 	}
 }
+
+func TestQueryURL_CanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := queryURL(ctx, &Credentials{}, "https://example.com", "GET", nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a context.Canceled error, got: %v", err)
+	}
+}
+
+func TestQueryURL_Headers(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") != "fossil-test" {
+			t.Errorf("Unexpected user agent: %s", r.Header.Get("User-Agent"))
+		}
+
+		if r.Header.Get("X-Test") != "1" {
+			t.Errorf("Base header not sent")
+		}
+
+		if r.Header.Get("Authorization") != "Bearer TESTTOKEN" {
+			t.Errorf("Base headers overrode the authorization: %s", r.Header.Get("Authorization"))
+		}
+
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	c := newCredentials(srv.URL, "TESTTOKEN", []Option{
+		WithUserAgent("fossil-test"),
+		WithBaseHeaders(http.Header{"X-Test": []string{"1"}, "Authorization": []string{"Bearer OTHER"}}),
+	})
+
+	_, err := queryURL(context.Background(), c, srv.URL, "GET", nil)
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+}
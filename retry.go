@@ -0,0 +1,170 @@
+package fossil
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxBackoff bounds the backoff delay of policies without a MaxDelay, so the doubling never overflows
+const maxBackoff = time.Hour
+
+//***** Structures *****//
+
+// RetryPolicy defines how failed requests are retried. Rate-limited requests (429) are always retried, since the
+// panel didn't process them, while network errors and 502, 503 and 504 responses are only retried for idempotent
+// methods unless RetryNonIdempotent is set.
+type RetryPolicy struct {
+	MaxAttempts        int           // Total number of attempts, including the first one
+	BaseDelay          time.Duration // Delay before the first retry, doubled on every following one
+	MaxDelay           time.Duration // Upper bound for the backoff delay, an hour if unset. Retry-After is always honored.
+	RetryNonIdempotent bool          // Also retry POST and PATCH requests on network and server errors
+	OnRetry            func(RetryInfo)
+}
+
+// RetryInfo describes a retry about to happen, and is passed to RetryPolicy.OnRetry
+type RetryInfo struct {
+	Attempt int // The attempt that failed, starting at 1
+	Method  string
+	URL     string
+	Delay   time.Duration // Time until the next attempt
+	Err     error         // Error of the failed attempt
+}
+
+// DefaultRetryPolicy returns a policy with 4 attempts and exponential backoff starting at half a second
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 4,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    30 * time.Second,
+	}
+}
+
+//***** Options *****//
+
+// WithRetry enables the retry of failed requests following the given policy. Retries are disabled by default.
+func WithRetry(policy RetryPolicy) Option {
+	return func(cfg *config) {
+		cfg.retry = &policy
+	}
+}
+
+//***** Retries *****//
+
+// next decides if the failed attempt should be retried, and how long to wait before doing so
+func (p *RetryPolicy) next(attempt int, method string, rp *http.Response, err error) (time.Duration, bool) {
+	if p == nil || err == nil || attempt >= p.MaxAttempts {
+		return 0, false
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return 0, false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests:
+			// Not processed by the panel, safe to retry for any method
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			if !p.RetryNonIdempotent && !isIdempotent(method) {
+				return 0, false
+			}
+		default:
+			return 0, false
+		}
+	} else if !p.RetryNonIdempotent && !isIdempotent(method) {
+		// Network error, the request might have reached the panel
+		return 0, false
+	}
+
+	if d, ok := retryAfter(rp); ok {
+		return d, true
+	}
+
+	return p.backoff(attempt), true
+}
+
+// backoff calculates the exponential delay for the attempt, with jitter to avoid synchronized retries
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	limit := p.MaxDelay
+	if limit <= 0 {
+		limit = maxBackoff
+	}
+
+	d := p.BaseDelay
+	for i := 1; i < attempt && d < limit; i++ {
+		d *= 2
+	}
+
+	if d > limit {
+		d = limit
+	}
+
+	if d <= 0 {
+		return 0
+	}
+
+	// Half of the delay is fixed and the other half random
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)+1))
+}
+
+// retryAfter reads how long the panel asked to wait from the Retry-After or X-RateLimit-Reset headers
+func retryAfter(rp *http.Response) (time.Duration, bool) {
+	if rp == nil {
+		return 0, false
+	}
+
+	if v := rp.Header.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil {
+			return time.Duration(secs) * time.Second, true
+		}
+
+		if t, err := http.ParseTime(v); err == nil {
+			return nonNegative(time.Until(t)), true
+		}
+	}
+
+	if rp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(rp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return nonNegative(time.Until(time.Unix(reset, 0))), true
+		}
+	}
+
+	return 0, false
+}
+
+// isIdempotent reports if a request with the method can be safely repeated
+func isIdempotent(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	}
+
+	return false
+}
+
+func nonNegative(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+
+	return d
+}
+
+// sleep waits for the duration or until the context is done
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
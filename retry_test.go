@@ -0,0 +1,104 @@
+package fossil
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+//***** Testing *****//
+
+func TestQueryURL_RetryRateLimited(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	var retries []RetryInfo
+	c := newCredentials(srv.URL, "", []Option{WithRetry(RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		OnRetry: func(info RetryInfo) {
+			retries = append(retries, info)
+		},
+	})})
+
+	// Rate limited requests are retried even if not idempotent
+	_, err := queryURL(context.Background(), c, srv.URL, "POST", nil)
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	if len(retries) != 2 {
+		t.Fatalf("Expected 2 retries, got %d", len(retries))
+	}
+
+	if retries[1].Attempt != 2 || retries[1].Delay != 0 {
+		t.Errorf("Unexpected retry info: %+v", retries[1])
+	}
+}
+
+func TestQueryURL_RetryGivesUp(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	c := newCredentials(srv.URL, "", []Option{WithRetry(RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+	})})
+
+	_, err := queryURL(context.Background(), c, srv.URL, "GET", nil)
+	if err == nil {
+		t.Error("Expected an error")
+	}
+
+	if calls != 3 {
+		t.Errorf("Expected 3 attempts, got %d", calls)
+	}
+
+	// Server errors on non-idempotent methods are not retried by default
+	calls = 0
+	_, _ = queryURL(context.Background(), c, srv.URL, "POST", nil)
+	if calls != 1 {
+		t.Errorf("Expected 1 attempt, got %d", calls)
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := RetryPolicy{
+		BaseDelay: 100 * time.Millisecond,
+		MaxDelay:  time.Second,
+	}
+
+	for attempt, max := range map[int]time.Duration{1: 100, 2: 200, 3: 400, 10: 1000} {
+		d := p.backoff(attempt)
+		max *= time.Millisecond
+		if d < max/2 || d > max {
+			t.Errorf("Backoff for attempt %d out of range: %s", attempt, d)
+		}
+	}
+}
+
+func TestRetryPolicy_BackoffNoMaxDelay(t *testing.T) {
+	p := RetryPolicy{BaseDelay: time.Second}
+
+	// Doubling the delay this many times would overflow without a bound
+	d := p.backoff(1000)
+	if d < maxBackoff/2 || d > maxBackoff {
+		t.Errorf("Backoff out of range: %s", d)
+	}
+}
@@ -0,0 +1,326 @@
+package fossil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// TaskAction is what a task does when it runs
+type TaskAction string
+
+// Task actions
+const (
+	TaskCommand TaskAction = "command" // Sends the payload as a console command
+	TaskPower   TaskAction = "power"   // Sends the payload, a PowerSignal like string(RESTART), as a power signal
+	TaskBackup  TaskAction = "backup"  // Creates a backup, the payload holds the ignored files
+)
+
+//***** Structures *****//
+
+// Schedule is a set of tasks run periodically on a server
+type Schedule struct {
+	ID             int        `json:"id"`
+	Name           string     `json:"name"`
+	Cron           Cron       `json:"cron"`
+	IsActive       bool       `json:"is_active"`
+	IsProcessing   bool       `json:"is_processing"`
+	OnlyWhenOnline bool       `json:"only_when_online"` // Skips the runs while the server is offline
+	LastRunAt      *time.Time `json:"last_run_at"`
+	NextRunAt      *time.Time `json:"next_run_at"`
+	Tasks          []*Task    `json:"-"` // Only set when fetched from the API
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// Cron holds when a schedule runs. Each field takes the usual cron syntax, such as "*", "*/5" or "1-5".
+type Cron struct {
+	Minute     string `json:"minute"`
+	Hour       string `json:"hour"`
+	DayOfMonth string `json:"day_of_month"`
+	Month      string `json:"month"`
+	DayOfWeek  string `json:"day_of_week"`
+}
+
+// Task is a single step of a schedule. The tasks run in order of sequence, each one waiting its time offset.
+type Task struct {
+	ID                int        `json:"id"`
+	SequenceID        int        `json:"sequence_id"`
+	Action            TaskAction `json:"action"`
+	Payload           string     `json:"payload"`     // The command, the power signal or the ignored files
+	TimeOffset        int        `json:"time_offset"` // Seconds to wait after the previous task
+	IsQueued          bool       `json:"is_queued"`
+	ContinueOnFailure bool       `json:"continue_on_failure"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// jsonSchedule contains the schedule info and its tasks.
+// It's used as the target struct in the unmarshalling of API responses.
+type jsonSchedule struct {
+	Schedule
+	Relationships struct {
+		Tasks struct {
+			Data []struct {
+				Task *Task `json:"attributes"`
+			} `json:"data"`
+		} `json:"tasks"`
+	} `json:"relationships"`
+}
+
+// jsonScheduleCreation stores the schedule info in an API-ready format for schedule creation and modification
+type jsonScheduleCreation struct {
+	Name           string `json:"name"`
+	Minute         string `json:"minute"`
+	Hour           string `json:"hour"`
+	DayOfMonth     string `json:"day_of_month"`
+	Month          string `json:"month"`
+	DayOfWeek      string `json:"day_of_week"`
+	IsActive       bool   `json:"is_active"`
+	OnlyWhenOnline bool   `json:"only_when_online"`
+}
+
+// jsonTaskCreation stores the task info in an API-ready format for task creation and modification
+type jsonTaskCreation struct {
+	Action            TaskAction `json:"action"`
+	Payload           string     `json:"payload"`
+	TimeOffset        int        `json:"time_offset"`
+	SequenceID        int        `json:"sequence_id,omitempty"`
+	ContinueOnFailure bool       `json:"continue_on_failure"`
+}
+
+//***** Converters *****//
+
+// asSchedule parses a jsonSchedule into a *Schedule
+func (s *jsonSchedule) asSchedule() *Schedule {
+	schedule := s.Schedule
+	for _, t := range s.Relationships.Tasks.Data {
+		if t.Task != nil {
+			schedule.Tasks = append(schedule.Tasks, t.Task)
+		}
+	}
+
+	return &schedule
+}
+
+// asJSONScheduleCreation parses a Schedule into a JSON-ready *jsonScheduleCreation
+func (s *Schedule) asJSONScheduleCreation() *jsonScheduleCreation {
+	return &jsonScheduleCreation{
+		Name:           s.Name,
+		Minute:         s.Cron.Minute,
+		Hour:           s.Cron.Hour,
+		DayOfMonth:     s.Cron.DayOfMonth,
+		Month:          s.Cron.Month,
+		DayOfWeek:      s.Cron.DayOfWeek,
+		IsActive:       s.IsActive,
+		OnlyWhenOnline: s.OnlyWhenOnline,
+	}
+}
+
+// asJSONTaskCreation parses a Task into a JSON-ready *jsonTaskCreation
+func (t *Task) asJSONTaskCreation() *jsonTaskCreation {
+	return &jsonTaskCreation{
+		Action:            t.Action,
+		Payload:           t.Payload,
+		TimeOffset:        t.TimeOffset,
+		SequenceID:        t.SequenceID,
+		ContinueOnFailure: t.ContinueOnFailure,
+	}
+}
+
+// decodeSchedule parses a single schedule response
+func decodeSchedule(bytes []byte) (*Schedule, error) {
+	var wrapper struct {
+		Schedule *jsonSchedule `json:"attributes"`
+	}
+
+	err := json.Unmarshal(bytes, &wrapper)
+	if err != nil {
+		return nil, err
+	}
+
+	if wrapper.Schedule == nil {
+		return nil, nil
+	}
+
+	return wrapper.Schedule.asSchedule(), nil
+}
+
+//***** String *****//
+
+func (s *Schedule) String() string {
+	return s.Name
+}
+
+func (c Cron) String() string {
+	return fmt.Sprintf("%s %s %s %s %s", c.Minute, c.Hour, c.DayOfMonth, c.Month, c.DayOfWeek)
+}
+
+//***** Requests *****//
+
+// ListSchedules fetches all the schedules of a server, along with their tasks
+func (c *ClientCredentials) ListSchedules(id string) ([]*Schedule, error) {
+	return c.ListSchedulesContext(context.Background(), id)
+}
+
+// ListSchedulesContext is ListSchedules with a context
+func (c *ClientCredentials) ListSchedulesContext(ctx context.Context, id string) (schedules []*Schedule, err error) {
+	bytes, err := c.query(ctx, "servers/"+id+"/schedules", "GET", nil)
+	if err != nil {
+		return
+	}
+
+	var wrapper struct {
+		Data []struct {
+			Schedule *jsonSchedule `json:"attributes"`
+		} `json:"data"`
+	}
+
+	err = json.Unmarshal(bytes, &wrapper)
+	if err != nil {
+		return
+	}
+
+	for _, d := range wrapper.Data {
+		if d.Schedule != nil {
+			schedules = append(schedules, d.Schedule.asSchedule())
+		}
+	}
+
+	return schedules, nil
+}
+
+// GetSchedule fetches a schedule of a server, along with its tasks
+func (c *ClientCredentials) GetSchedule(id string, scheduleID int) (*Schedule, error) {
+	return c.GetScheduleContext(context.Background(), id, scheduleID)
+}
+
+// GetScheduleContext is GetSchedule with a context
+func (c *ClientCredentials) GetScheduleContext(ctx context.Context, id string, scheduleID int) (*Schedule, error) {
+	bytes, err := c.query(ctx, fmt.Sprintf("servers/%s/schedules/%d", id, scheduleID), "GET", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeSchedule(bytes)
+}
+
+// CreateSchedule makes a new schedule on a server and returns it as created by the panel. Tasks must be added
+// afterwards with CreateTask.
+func (c *ClientCredentials) CreateSchedule(id string, s *Schedule) (*Schedule, error) {
+	return c.CreateScheduleContext(context.Background(), id, s)
+}
+
+// CreateScheduleContext is CreateSchedule with a context
+func (c *ClientCredentials) CreateScheduleContext(ctx context.Context, id string,
+	s *Schedule) (created *Schedule, err error) {
+	rq, err := json.Marshal(s.asJSONScheduleCreation())
+	if err != nil {
+		return
+	}
+
+	bytes, err := c.query(ctx, "servers/"+id+"/schedules", "POST", rq)
+	if err != nil {
+		return
+	}
+
+	return decodeSchedule(bytes)
+}
+
+// UpdateSchedule modifies the schedule as per the passed object. Modifiable parameters include: Name, Cron,
+// Is active and Only when online. The tasks are left untouched.
+func (c *ClientCredentials) UpdateSchedule(id string, s *Schedule) error {
+	return c.UpdateScheduleContext(context.Background(), id, s)
+}
+
+// UpdateScheduleContext is UpdateSchedule with a context
+func (c *ClientCredentials) UpdateScheduleContext(ctx context.Context, id string, s *Schedule) (err error) {
+	bytes, err := json.Marshal(s.asJSONScheduleCreation())
+	if err != nil {
+		return
+	}
+
+	_, err = c.query(ctx, fmt.Sprintf("servers/%s/schedules/%d", id, s.ID), "POST", bytes)
+	return
+}
+
+// DeleteSchedule deletes a schedule, along with its tasks
+func (c *ClientCredentials) DeleteSchedule(id string, scheduleID int) error {
+	return c.DeleteScheduleContext(context.Background(), id, scheduleID)
+}
+
+// DeleteScheduleContext is DeleteSchedule with a context
+func (c *ClientCredentials) DeleteScheduleContext(ctx context.Context, id string, scheduleID int) (err error) {
+	_, err = c.query(ctx, fmt.Sprintf("servers/%s/schedules/%d", id, scheduleID), "DELETE", nil)
+	return
+}
+
+// ExecuteSchedule triggers a schedule now, regardless of its cron
+func (c *ClientCredentials) ExecuteSchedule(id string, scheduleID int) error {
+	return c.ExecuteScheduleContext(context.Background(), id, scheduleID)
+}
+
+// ExecuteScheduleContext is ExecuteSchedule with a context
+func (c *ClientCredentials) ExecuteScheduleContext(ctx context.Context, id string, scheduleID int) (err error) {
+	_, err = c.query(ctx, fmt.Sprintf("servers/%s/schedules/%d/execute", id, scheduleID), "POST", nil)
+	return
+}
+
+// CreateTask adds a task to a schedule and returns it as created by the panel
+func (c *ClientCredentials) CreateTask(id string, scheduleID int, t *Task) (*Task, error) {
+	return c.CreateTaskContext(context.Background(), id, scheduleID, t)
+}
+
+// CreateTaskContext is CreateTask with a context
+func (c *ClientCredentials) CreateTaskContext(ctx context.Context, id string, scheduleID int,
+	t *Task) (created *Task, err error) {
+	rq, err := json.Marshal(t.asJSONTaskCreation())
+	if err != nil {
+		return
+	}
+
+	bytes, err := c.query(ctx, fmt.Sprintf("servers/%s/schedules/%d/tasks", id, scheduleID), "POST", rq)
+	if err != nil {
+		return
+	}
+
+	var wrapper struct {
+		Task *Task `json:"attributes"`
+	}
+
+	err = json.Unmarshal(bytes, &wrapper)
+	if err != nil {
+		return
+	}
+
+	return wrapper.Task, nil
+}
+
+// UpdateTask modifies the task as per the passed object. Modifiable parameters include: Action, Payload,
+// Time offset, Sequence ID and Continue on failure.
+func (c *ClientCredentials) UpdateTask(id string, scheduleID int, t *Task) error {
+	return c.UpdateTaskContext(context.Background(), id, scheduleID, t)
+}
+
+// UpdateTaskContext is UpdateTask with a context
+func (c *ClientCredentials) UpdateTaskContext(ctx context.Context, id string, scheduleID int, t *Task) (err error) {
+	bytes, err := json.Marshal(t.asJSONTaskCreation())
+	if err != nil {
+		return
+	}
+
+	_, err = c.query(ctx, fmt.Sprintf("servers/%s/schedules/%d/tasks/%d", id, scheduleID, t.ID), "POST", bytes)
+	return
+}
+
+// DeleteTask removes a task from a schedule
+func (c *ClientCredentials) DeleteTask(id string, scheduleID, taskID int) error {
+	return c.DeleteTaskContext(context.Background(), id, scheduleID, taskID)
+}
+
+// DeleteTaskContext is DeleteTask with a context
+func (c *ClientCredentials) DeleteTaskContext(ctx context.Context, id string, scheduleID, taskID int) (err error) {
+	_, err = c.query(ctx, fmt.Sprintf("servers/%s/schedules/%d/tasks/%d", id, scheduleID, taskID), "DELETE", nil)
+	return
+}
@@ -0,0 +1,498 @@
+package fossil
+
+import (
+	"context"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+	"time"
+)
+
+//***** Testing *****//
+
+func TestClientCredentials_ListSchedules(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/schedules"
+		if expectURL != url {
+			t.Errorf("Request url does not match expected: %s", url)
+		}
+
+		// Entries without attributes are skipped
+		return []byte(`{"object":"list","data":[{
+		  "object": "server_schedule",
+		  "attributes": {
+			"id": 4,
+			"name": "Nightly restart",
+			"cron": {
+			  "day_of_week": "*",
+			  "day_of_month": "*",
+			  "month": "*",
+			  "hour": "4",
+			  "minute": "0"
+			},
+			"is_active": true,
+			"is_processing": false,
+			"only_when_online": true,
+			"last_run_at": null,
+			"next_run_at": "2020-11-03T04:00:00+00:00",
+			"created_at": "2020-11-02T12:21:08+00:00",
+			"updated_at": "2020-11-02T12:21:08+00:00",
+			"relationships": {
+			  "tasks": {
+				"object": "list",
+				"data": [
+				  {
+					"object": "schedule_task",
+					"attributes": {
+					  "id": 9,
+					  "sequence_id": 1,
+					  "action": "power",
+					  "payload": "restart",
+					  "time_offset": 0,
+					  "is_queued": false,
+					  "continue_on_failure": false,
+					  "created_at": "2020-11-02T12:21:08+00:00",
+					  "updated_at": "2020-11-02T12:21:08+00:00"
+					}
+				  }
+				]
+			  }
+			}
+		  }
+		},{}]}`), nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	got, err := c.ListSchedules("1a7ce997")
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	next, _ := time.Parse(time.RFC3339, "2020-11-03T04:00:00+00:00")
+	created, _ := time.Parse(time.RFC3339, "2020-11-02T12:21:08+00:00")
+
+	expect := []*Schedule{
+		{
+			ID:   4,
+			Name: "Nightly restart",
+			Cron: Cron{
+				Minute:     "0",
+				Hour:       "4",
+				DayOfMonth: "*",
+				Month:      "*",
+				DayOfWeek:  "*",
+			},
+			IsActive:       true,
+			OnlyWhenOnline: true,
+			NextRunAt:      &next,
+			Tasks: []*Task{
+				{
+					ID:         9,
+					SequenceID: 1,
+					Action:     TaskPower,
+					Payload:    string(RESTART),
+					CreatedAt:  created,
+					UpdatedAt:  created,
+				},
+			},
+			CreatedAt: created,
+			UpdatedAt: created,
+		},
+	}
+
+	if !cmp.Equal(got, expect) {
+		t.Errorf("Unexpected response: %s", cmp.Diff(got, expect))
+	}
+}
+
+func TestClientCredentials_GetSchedule(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/schedules/4"
+		if expectURL != url {
+			t.Errorf("Request url does not match expected: %s", url)
+		}
+
+		res := `{
+		  "object": "server_schedule",
+		  "attributes": {
+			"id": 4,
+			"name": "Nightly restart",
+			"cron": {
+			  "day_of_week": "*",
+			  "day_of_month": "*",
+			  "month": "*",
+			  "hour": "4",
+			  "minute": "0"
+			},
+			"is_active": true,
+			"is_processing": false,
+			"only_when_online": true,
+			"last_run_at": null,
+			"next_run_at": "2020-11-03T04:00:00+00:00",
+			"created_at": "2020-11-02T12:21:08+00:00",
+			"updated_at": "2020-11-02T12:21:08+00:00",
+			"relationships": {
+			  "tasks": {
+				"object": "list",
+				"data": [
+				  {
+					"object": "schedule_task",
+					"attributes": {
+					  "id": 9,
+					  "sequence_id": 1,
+					  "action": "power",
+					  "payload": "restart",
+					  "time_offset": 0,
+					  "is_queued": false,
+					  "continue_on_failure": false,
+					  "created_at": "2020-11-02T12:21:08+00:00",
+					  "updated_at": "2020-11-02T12:21:08+00:00"
+					}
+				  }
+				]
+			  }
+			}
+		  }
+		}`
+
+		return []byte(res), nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	got, err := c.GetSchedule("1a7ce997", 4)
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	next, _ := time.Parse(time.RFC3339, "2020-11-03T04:00:00+00:00")
+	created, _ := time.Parse(time.RFC3339, "2020-11-02T12:21:08+00:00")
+
+	expect := &Schedule{
+		ID:   4,
+		Name: "Nightly restart",
+		Cron: Cron{
+			Minute:     "0",
+			Hour:       "4",
+			DayOfMonth: "*",
+			Month:      "*",
+			DayOfWeek:  "*",
+		},
+		IsActive:       true,
+		OnlyWhenOnline: true,
+		NextRunAt:      &next,
+		Tasks: []*Task{
+			{
+				ID:         9,
+				SequenceID: 1,
+				Action:     TaskPower,
+				Payload:    string(RESTART),
+				CreatedAt:  created,
+				UpdatedAt:  created,
+			},
+		},
+		CreatedAt: created,
+		UpdatedAt: created,
+	}
+
+	if !cmp.Equal(got, expect) {
+		t.Errorf("Unexpected response: %s", cmp.Diff(got, expect))
+	}
+}
+
+func TestClientCredentials_CreateSchedule(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/schedules"
+		if expectURL != url || method != "POST" {
+			t.Errorf("Request url does not match expected: %s %s", method, url)
+		}
+
+		expectBody := `{"name":"Nightly restart","minute":"0","hour":"4","day_of_month":"*","month":"*",` +
+			`"day_of_week":"*","is_active":true,"only_when_online":true}`
+		if expectBody != string(data) {
+			t.Errorf("Request body does not match expected: %s", data)
+		}
+
+		res := `{
+		  "object": "server_schedule",
+		  "attributes": {
+			"id": 4,
+			"name": "Nightly restart",
+			"cron": {
+			  "day_of_week": "*",
+			  "day_of_month": "*",
+			  "month": "*",
+			  "hour": "4",
+			  "minute": "0"
+			},
+			"is_active": true,
+			"is_processing": false,
+			"only_when_online": true,
+			"last_run_at": null,
+			"next_run_at": "2020-11-03T04:00:00+00:00",
+			"created_at": "2020-11-02T12:21:08+00:00",
+			"updated_at": "2020-11-02T12:21:08+00:00",
+			"relationships": {
+			  "tasks": {
+				"object": "list",
+				"data": [
+				  {
+					"object": "schedule_task",
+					"attributes": {
+					  "id": 9,
+					  "sequence_id": 1,
+					  "action": "power",
+					  "payload": "restart",
+					  "time_offset": 0,
+					  "is_queued": false,
+					  "continue_on_failure": false,
+					  "created_at": "2020-11-02T12:21:08+00:00",
+					  "updated_at": "2020-11-02T12:21:08+00:00"
+					}
+				  }
+				]
+			  }
+			}
+		  }
+		}`
+
+		return []byte(res), nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	s := &Schedule{
+		Name:           "Nightly restart",
+		Cron:           Cron{Minute: "0", Hour: "4", DayOfMonth: "*", Month: "*", DayOfWeek: "*"},
+		IsActive:       true,
+		OnlyWhenOnline: true,
+	}
+
+	got, err := c.CreateSchedule("1a7ce997", s)
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	next, _ := time.Parse(time.RFC3339, "2020-11-03T04:00:00+00:00")
+	created, _ := time.Parse(time.RFC3339, "2020-11-02T12:21:08+00:00")
+
+	expect := &Schedule{
+		ID:   4,
+		Name: "Nightly restart",
+		Cron: Cron{
+			Minute:     "0",
+			Hour:       "4",
+			DayOfMonth: "*",
+			Month:      "*",
+			DayOfWeek:  "*",
+		},
+		IsActive:       true,
+		OnlyWhenOnline: true,
+		NextRunAt:      &next,
+		Tasks: []*Task{
+			{
+				ID:         9,
+				SequenceID: 1,
+				Action:     TaskPower,
+				Payload:    string(RESTART),
+				CreatedAt:  created,
+				UpdatedAt:  created,
+			},
+		},
+		CreatedAt: created,
+		UpdatedAt: created,
+	}
+
+	if !cmp.Equal(got, expect) {
+		t.Errorf("Unexpected response: %s", cmp.Diff(got, expect))
+	}
+
+	if s.ID != 0 {
+		t.Errorf("The passed schedule was modified: %+v", s)
+	}
+}
+
+func TestClientCredentials_UpdateSchedule(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/schedules/4"
+		if expectURL != url || method != "POST" {
+			t.Errorf("Request url does not match expected: %s %s", method, url)
+		}
+
+		expectBody := `{"name":"Nightly restart","minute":"30","hour":"5","day_of_month":"*","month":"*",` +
+			`"day_of_week":"1-5","is_active":false,"only_when_online":false}`
+		if expectBody != string(data) {
+			t.Errorf("Request body does not match expected: %s", data)
+		}
+
+		res := `{
+		  "object": "server_schedule",
+		  "attributes": {
+			"id": 4,
+			"name": "Nightly restart",
+			"cron": {
+			  "day_of_week": "*",
+			  "day_of_month": "*",
+			  "month": "*",
+			  "hour": "4",
+			  "minute": "0"
+			},
+			"is_active": true,
+			"is_processing": false,
+			"only_when_online": true,
+			"last_run_at": null,
+			"next_run_at": "2020-11-03T04:00:00+00:00",
+			"created_at": "2020-11-02T12:21:08+00:00",
+			"updated_at": "2020-11-02T12:21:08+00:00",
+			"relationships": {
+			  "tasks": {
+				"object": "list",
+				"data": [
+				  {
+					"object": "schedule_task",
+					"attributes": {
+					  "id": 9,
+					  "sequence_id": 1,
+					  "action": "power",
+					  "payload": "restart",
+					  "time_offset": 0,
+					  "is_queued": false,
+					  "continue_on_failure": false,
+					  "created_at": "2020-11-02T12:21:08+00:00",
+					  "updated_at": "2020-11-02T12:21:08+00:00"
+					}
+				  }
+				]
+			  }
+			}
+		  }
+		}`
+
+		return []byte(res), nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	err := c.UpdateSchedule("1a7ce997", &Schedule{
+		ID:   4,
+		Name: "Nightly restart",
+		Cron: Cron{Minute: "30", Hour: "5", DayOfMonth: "*", Month: "*", DayOfWeek: "1-5"},
+	})
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+}
+
+func TestClientCredentials_ExecuteSchedule(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/schedules/4/execute"
+		if expectURL != url || method != "POST" {
+			t.Errorf("Request url does not match expected: %s %s", method, url)
+		}
+
+		return nil, nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	err := c.ExecuteSchedule("1a7ce997", 4)
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+}
+
+func TestClientCredentials_DeleteSchedule(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/schedules/4"
+		if expectURL != url || method != "DELETE" {
+			t.Errorf("Request url does not match expected: %s %s", method, url)
+		}
+
+		return nil, nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	err := c.DeleteSchedule("1a7ce997", 4)
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+}
+
+func TestClientCredentials_CreateTask(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/schedules/4/tasks"
+		if expectURL != url || method != "POST" {
+			t.Errorf("Request url does not match expected: %s %s", method, url)
+		}
+
+		expectBody := `{"action":"command","payload":"say Restarting in 5 minutes","time_offset":0,` +
+			`"continue_on_failure":true}`
+		if expectBody != string(data) {
+			t.Errorf("Request body does not match expected: %s", data)
+		}
+
+		return []byte(`{"object":"schedule_task","attributes":{"id":10,"sequence_id":2,"action":"command",
+			"payload":"say Restarting in 5 minutes","time_offset":0,"continue_on_failure":true}}`), nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	task := &Task{Action: TaskCommand, Payload: "say Restarting in 5 minutes", ContinueOnFailure: true}
+
+	got, err := c.CreateTask("1a7ce997", 4, task)
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	if got.ID != 10 || got.SequenceID != 2 {
+		t.Errorf("Unexpected response: %+v", got)
+	}
+
+	if task.ID != 0 || task.SequenceID != 0 {
+		t.Errorf("The passed task was modified: %+v", task)
+	}
+}
+
+func TestClientCredentials_UpdateTask(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/schedules/4/tasks/10"
+		if expectURL != url || method != "POST" {
+			t.Errorf("Request url does not match expected: %s %s", method, url)
+		}
+
+		expectBody := `{"action":"power","payload":"restart","time_offset":300,"sequence_id":2,` +
+			`"continue_on_failure":false}`
+		if expectBody != string(data) {
+			t.Errorf("Request body does not match expected: %s", data)
+		}
+
+		return nil, nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	err := c.UpdateTask("1a7ce997", 4, &Task{ID: 10, SequenceID: 2, Action: TaskPower, Payload: string(RESTART),
+		TimeOffset: 300})
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+}
+
+func TestClientCredentials_DeleteTask(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/schedules/4/tasks/10"
+		if expectURL != url || method != "DELETE" {
+			t.Errorf("Request url does not match expected: %s %s", method, url)
+		}
+
+		return nil, nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	err := c.DeleteTask("1a7ce997", 4, 10)
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+}
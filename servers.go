@@ -1,6 +1,7 @@
 package fossil
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -8,12 +9,26 @@ import (
 
 //***** Structures *****//
 
+// PowerSignal is a power action sent to a server
+type PowerSignal string
+
 // Power States
 const (
-	ON      = "start"
-	OFF     = "stop"
-	RESTART = "restart"
-	KILL    = "kill"
+	ON      PowerSignal = "start"
+	OFF     PowerSignal = "stop"
+	RESTART PowerSignal = "restart"
+	KILL    PowerSignal = "kill"
+)
+
+// ServerState is the power state of a server
+type ServerState string
+
+// Server States
+const (
+	StateRunning  ServerState = "running"
+	StateOffline  ServerState = "offline"
+	StateStarting ServerState = "starting"
+	StateStopping ServerState = "stopping"
 )
 
 // Servers
@@ -21,11 +36,24 @@ const (
 // ClientServer defines Pterodactyl server as a user would see it. It is fetched and interacted with the client token.
 type ClientServer struct {
 	ID                string
+	UUID              string
 	Name              string
 	Description       string
+	Node              string // Name of the node
+	SFTP              SFTPDetails
+	Status            string // Empty when the server is ready, or "installing", "suspended"...
+	DockerImage       string
 	Limits            Limits
 	AllocationDetails []Allocation
 	IsOwner           bool
+	IsSuspended       bool
+	IsInstalling      bool
+}
+
+// SFTPDetails is the address of the SFTP server giving access to the files of a server
+type SFTPDetails struct {
+	IP   string `json:"ip"`
+	Port int    `json:"port"`
 }
 
 // ApplicationServer defines Pterodactyl server as an administrator would see it. It is fetched and interacted with
@@ -46,10 +74,19 @@ type ApplicationServer struct {
 	Allocation         int
 	AllocationsDetails []Allocation
 	Container          Container
+	Deploy             *Deploy // Only used on creation, see Deploy
 	Updated            time.Time
 	Created            time.Time
 }
 
+// Deploy lets the panel choose the node and allocation of a new server, out of the nodes in the given locations
+// with enough capacity. When set in a server passed to CreateServer the Node and Allocation are ignored.
+type Deploy struct {
+	Locations   []int    `json:"locations"`
+	DedicatedIP bool     `json:"dedicated_ip"`
+	PortRange   []string `json:"port_range"` // Single ports or ranges, like "25565-25600"
+}
+
 // jsonServer is the API definition for the server, and contains all the data in it's original form.
 // It's used as the target struct in the marshalling/unmarshalling of API requests or responses.
 type jsonServer struct {
@@ -84,6 +121,34 @@ type jsonServer struct {
 	} `json:"relationships"`
 }
 
+// jsonClientServer is the client API definition for the server. Unlike in the application API, the node is
+// given by its name. It's used as the target struct in the unmarshalling of API responses.
+type jsonClientServer struct {
+	Identifier    string      `json:"identifier"`
+	UUID          string      `json:"uuid"`
+	Name          string      `json:"name"`
+	Description   string      `json:"description"`
+	Node          string      `json:"node"`
+	SFTPDetails   SFTPDetails `json:"sftp_details"`
+	Status        string      `json:"status"`
+	DockerImage   string      `json:"docker_image"`
+	ServerOwner   bool        `json:"server_owner"`
+	IsSuspended   bool        `json:"is_suspended"`
+	IsInstalling  bool        `json:"is_installing"`
+	Limits        Limits      `json:"limits"`
+	FeatureLimits struct {
+		Databases   int `json:"databases"`
+		Allocations int `json:"allocations"`
+	} `json:"feature_limits"`
+	Relationships struct {
+		Allocations struct {
+			Data []struct {
+				Allocation *Allocation `json:"attributes"`
+			} `json:"data"`
+		} `json:"allocations"`
+	} `json:"relationships"`
+}
+
 // jsonServerCreation stores the server info in an API-ready format for server creation
 type jsonServerCreation struct {
 	ExternalID    string            `json:"external_id"`
@@ -104,17 +169,9 @@ type jsonServerCreation struct {
 		Default    int   `json:"default,omitempty"`
 		Additional []int `json:"additional,omitempty"`
 	} `json:"allocation"`
-	Nest int `json:"nest"`
-	Egg  int `json:"egg"`
-}
-
-// jsonServerPage contains a page of jsonServers and the pagination data.
-// It's used as the target struct in the marshalling/unmarshalling of API requests or responses.
-type jsonServerPage struct {
-	Data []struct {
-		Server *jsonServer `json:"attributes"`
-	} `json:"data"`
-	Meta Meta `json:"meta"`
+	Deploy *Deploy `json:"deploy,omitempty"`
+	Nest   int     `json:"nest"`
+	Egg    int     `json:"egg"`
 }
 
 // Limits contains all the allocated usage limits set for a server
@@ -138,27 +195,39 @@ type Container struct {
 
 // Allocation holds all the information relating to the allocation data of a server
 type Allocation struct {
+	ID      int    `json:"id"`
 	Primary bool   `json:"primary"`
 	IP      string `json:"ip"`
 	Alias   string `json:"alias"`
 	Port    int    `json:"port"`
+	Notes   string `json:"notes"`
 }
 
 // Status
 
-// ServerStatus contains the client-visible server usage information
+// ServerStatus contains the client-visible server usage information. The memory and disk are always given in
+// bytes, whichever endpoint the panel has. Newer panels don't give the limits, the CPU cores nor the players.
 type ServerStatus struct {
-	State   string
-	Memory  Memory
-	CPU     CPU
-	Disk    Disk
-	Players Players
+	State       ServerState
+	IsSuspended bool
+	Memory      Memory
+	CPU         CPU
+	Disk        Disk
+	Network     Network
+	Uptime      time.Duration
+	Players     Players
 }
 
 // Memory holds the usage and limits of the server memory
 type Memory struct {
-	Used  uint64
-	Limit uint64
+	Used  uint64 // Bytes
+	Limit uint64 // Bytes
+}
+
+// Network holds the traffic of the server since it started
+type Network struct {
+	RxBytes uint64
+	TxBytes uint64
 }
 
 // CPU holds the usage, core status and limits of the server CPU
@@ -170,8 +239,8 @@ type CPU struct {
 
 // Disk holds the usage and limits of the server disk
 type Disk struct {
-	Used  uint64
-	Limit uint64
+	Used  uint64 // Bytes
+	Limit uint64 // Bytes
 }
 
 // Players holds the number of users in a server and the max amount permitted. Some Pterodactyl API-based
@@ -181,16 +250,126 @@ type Players struct {
 	Limit   uint64
 }
 
+// jsonResources is the server usage as given by the resources endpoint of newer panels.
+// It's used as the target struct in the unmarshalling of API responses.
+type jsonResources struct {
+	CurrentState ServerState `json:"current_state"`
+	IsSuspended  bool        `json:"is_suspended"`
+	Resources    struct {
+		MemoryBytes    uint64  `json:"memory_bytes"`
+		CPUAbsolute    float32 `json:"cpu_absolute"`
+		DiskBytes      uint64  `json:"disk_bytes"`
+		NetworkRxBytes uint64  `json:"network_rx_bytes"`
+		NetworkTxBytes uint64  `json:"network_tx_bytes"`
+		Uptime         int64   `json:"uptime"` // Milliseconds
+	} `json:"resources"`
+}
+
+// jsonUtilization is the server usage as given by the utilization endpoint of older panels, in megabytes.
+// It's used as the target struct in the unmarshalling of API responses.
+type jsonUtilization struct {
+	State  ServerState `json:"state"`
+	Memory struct {
+		Current uint64 `json:"current"`
+		Limit   uint64 `json:"limit"`
+	} `json:"memory"`
+	CPU struct {
+		Current float32   `json:"current"`
+		Cores   []float32 `json:"cores"`
+		Limit   uint64    `json:"limit"`
+	} `json:"cpu"`
+	Disk struct {
+		Current uint64 `json:"current"`
+		Limit   uint64 `json:"limit"`
+	} `json:"disk"`
+	Players struct {
+		Current uint64 `json:"current"`
+		Limit   uint64 `json:"limit"`
+	} `json:"players"`
+}
+
 //***** Converters *****//
 
-// asClientServer parses a jsonServer into a *ClientServer
-func (s *jsonServer) asClientServer() *ClientServer {
+// asServerStatus parses a jsonResources into a *ServerStatus
+func (r *jsonResources) asServerStatus() *ServerStatus {
+	return &ServerStatus{
+		State:       r.CurrentState,
+		IsSuspended: r.IsSuspended,
+		Memory:      Memory{Used: r.Resources.MemoryBytes},
+		CPU:         CPU{Current: r.Resources.CPUAbsolute},
+		Disk:        Disk{Used: r.Resources.DiskBytes},
+		Network:     Network{RxBytes: r.Resources.NetworkRxBytes, TxBytes: r.Resources.NetworkTxBytes},
+		Uptime:      time.Duration(r.Resources.Uptime) * time.Millisecond,
+	}
+}
+
+// asServerStatus parses a jsonUtilization into a *ServerStatus, converting the megabytes to bytes
+func (u *jsonUtilization) asServerStatus() *ServerStatus {
+	const mb = 1024 * 1024
+
+	return &ServerStatus{
+		State: normalizeState(u.State),
+		Memory: Memory{
+			Used:  u.Memory.Current * mb,
+			Limit: u.Memory.Limit * mb,
+		},
+		CPU: CPU{
+			Current: u.CPU.Current,
+			Cores:   u.CPU.Cores,
+			Limit:   u.CPU.Limit,
+		},
+		Disk: Disk{
+			Used:  u.Disk.Current * mb,
+			Limit: u.Disk.Limit * mb,
+		},
+		Players: Players{
+			Current: u.Players.Current,
+			Limit:   u.Players.Limit,
+		},
+	}
+}
+
+// UnmarshalJSON parses an allocation. Newer panels name the primary and alias fields is_default and ip_alias.
+func (a *Allocation) UnmarshalJSON(data []byte) error {
+	type allocation Allocation // Avoids the recursion into UnmarshalJSON
+	var v struct {
+		allocation
+		IsDefault *bool   `json:"is_default"`
+		IPAlias   *string `json:"ip_alias"`
+	}
+
+	err := json.Unmarshal(data, &v)
+	if err != nil {
+		return err
+	}
+
+	*a = Allocation(v.allocation)
+	if v.IsDefault != nil {
+		a.Primary = *v.IsDefault
+	}
+
+	if v.IPAlias != nil {
+		a.Alias = *v.IPAlias
+	}
+
+	return nil
+}
+
+// asClientServer parses a jsonClientServer into a *ClientServer
+func (s *jsonClientServer) asClientServer() *ClientServer {
 	cs := &ClientServer{
-		ID:          s.Identifier,
-		Name:        s.Name,
-		Description: s.Description,
-		Limits:      s.Limits,
-		IsOwner:     s.ServerOwner,
+		ID:           s.Identifier,
+		UUID:         s.UUID,
+		Name:         s.Name,
+		Description:  s.Description,
+		Node:         s.Node,
+		SFTP:         s.SFTPDetails,
+		Status:       s.Status,
+		DockerImage:  s.DockerImage,
+		Limits:       s.Limits,
+		IsOwner:      s.ServerOwner,
+		IsSuspended:  s.IsSuspended,
+		IsInstalling: s.IsInstalling,
 	}
 	cs.Limits.Databases = s.FeatureLimits.Databases
 
@@ -201,15 +380,6 @@ func (s *jsonServer) asClientServer() *ClientServer {
 	return cs
 }
 
-// asClientServers parses a jsonServerPage into an slice of *ClientServers
-func (sp *jsonServerPage) asClientServers() (servers []*ClientServer) {
-	for _, d := range sp.Data {
-		servers = append(servers, d.Server.asClientServer())
-	}
-
-	return servers
-}
-
 // asApplicationServer parses a jsonServer into an *ApplicationServer
 func (s *jsonServer) asApplicationServer() *ApplicationServer {
 	as := &ApplicationServer{
@@ -240,15 +410,6 @@ func (s *jsonServer) asApplicationServer() *ApplicationServer {
 	return as
 }
 
-// asApplicationServers parses a jsonServerPage into an slice of *ApplicationServer
-func (sp *jsonServerPage) asApplicationServers() (servers []*ApplicationServer) {
-	for _, d := range sp.Data {
-		servers = append(servers, d.Server.asApplicationServer())
-	}
-
-	return servers
-}
-
 // asJSONServerCreation parses a ApplicationServer into a JSON-ready *jsonServerCreation
 func (s *ApplicationServer) asJSONServerCreation() *jsonServerCreation {
 	js := &jsonServerCreation{
@@ -272,6 +433,19 @@ func (s *ApplicationServer) asJSONServerCreation() *jsonServerCreation {
 	}
 
 	js.FeatureLimits.Databases = s.Limits.Databases
+
+	// The panel picks the allocation when deploying
+	if s.Deploy != nil {
+		d := *s.Deploy
+		if d.PortRange == nil {
+			d.PortRange = []string{}
+		}
+
+		js.Deploy = &d
+
+		return js
+	}
+
 	js.Allocation.Default = s.Allocation
 
 	for _, alloc := range s.AllocationsDetails {
@@ -295,33 +469,60 @@ func (s *ApplicationServer) String() string {
 
 //***** Pagination *****//
 
-// getAll fetches all the existing pages for a server list. The original page is kept as index 0
-func (sp *jsonServerPage) getAll(token string) (pages []*jsonServerPage, err error) {
-	pages = append(pages, sp)
-	for pages[len(pages)-1].Meta.Pagination.Links.Next != "" {
-		url := sp.Meta.Pagination.Links.Next + "&include=allocations"
-		bytes, err := queryURL(url, token, "GET", nil)
-		if err != nil {
-			return nil, err
-		}
+// ServerIterator lazily walks a list of servers as seen by an administrator, fetching the pages as needed
+type ServerIterator struct {
+	pager
+	server *jsonServer
+}
 
-		var page jsonServerPage
-		err = json.Unmarshal(bytes, &page)
-		if err != nil {
-			return nil, err
-		}
+// Next advances to the next server, returning false when there are no more or an error occurred
+func (it *ServerIterator) Next() bool {
+	var s jsonServer
+	if !it.next(&s) {
+		return false
+	}
+
+	it.server = &s
+	return true
+}
 
-		pages = append(pages, &page)
+// Value returns the current server
+func (it *ServerIterator) Value() *ApplicationServer {
+	return it.server.asApplicationServer()
+}
+
+// ClientServerIterator lazily walks a list of servers as seen by a user, fetching the pages as needed
+type ClientServerIterator struct {
+	pager
+	server *jsonClientServer
+}
+
+// Next advances to the next server, returning false when there are no more or an error occurred
+func (it *ClientServerIterator) Next() bool {
+	var s jsonClientServer
+	if !it.next(&s) {
+		return false
 	}
 
-	return pages, nil
+	it.server = &s
+	return true
+}
+
+// Value returns the current server
+func (it *ClientServerIterator) Value() *ClientServer {
+	return it.server.asClientServer()
 }
 
 //***** Requests *****//
 
 // GetServer fetches the server with the given Internal ID if it exists
-func (c *ApplicationCredentials) GetServer(internalID int) (sv *ApplicationServer, err error) {
-	bytes, err := c.query(fmt.Sprintf("servers/%d?include=allocations", internalID), "GET", nil)
+func (c *ApplicationCredentials) GetServer(internalID int) (*ApplicationServer, error) {
+	return c.GetServerContext(context.Background(), internalID)
+}
+
+// GetServerContext is GetServer with a context
+func (c *ApplicationCredentials) GetServerContext(ctx context.Context, internalID int) (sv *ApplicationServer, err error) {
+	bytes, err := c.query(ctx, fmt.Sprintf("servers/%d?include=allocations", internalID), "GET", nil)
 	if err != nil {
 		return
 	}
@@ -339,8 +540,13 @@ func (c *ApplicationCredentials) GetServer(internalID int) (sv *ApplicationServe
 }
 
 // GetServerExternal fetches the server with the given External ID if it exists
-func (c *ApplicationCredentials) GetServerExternal(externalID string) (sv *ApplicationServer, err error) {
-	bytes, err := c.query("servers/external/"+externalID+"?include=allocations", "GET", nil)
+func (c *ApplicationCredentials) GetServerExternal(externalID string) (*ApplicationServer, error) {
+	return c.GetServerExternalContext(context.Background(), externalID)
+}
+
+// GetServerExternalContext is GetServerExternal with a context
+func (c *ApplicationCredentials) GetServerExternalContext(ctx context.Context, externalID string) (sv *ApplicationServer, err error) {
+	bytes, err := c.query(ctx, "servers/external/"+externalID+"?include=allocations", "GET", nil)
 	if err != nil {
 		return
 	}
@@ -357,50 +563,82 @@ func (c *ApplicationCredentials) GetServerExternal(externalID string) (sv *Appli
 	return wrapper.Server.asApplicationServer(), nil
 }
 
-// GetServers fetches the servers of all the users
-func (c *ApplicationCredentials) GetServers() (svs []*ApplicationServer, err error) {
-	bytes, err := c.query("servers?include=allocations", "GET", nil)
-	if err != nil {
-		return
+// GetServers fetches the servers of all the users. The results can be optionally filtered and sorted with
+// ListOptions.
+func (c *ApplicationCredentials) GetServers(opts ...*ListOptions) ([]*ApplicationServer, error) {
+	return c.GetServersContext(context.Background(), opts...)
+}
+
+// GetServersContext is GetServers with a context
+func (c *ApplicationCredentials) GetServersContext(ctx context.Context, opts ...*ListOptions) (svs []*ApplicationServer, err error) {
+	it := c.ServersIterContext(ctx, listOptions(opts))
+	for it.Next() {
+		svs = append(svs, it.Value())
 	}
 
-	// Get the initial page
-	var page jsonServerPage
-	err = json.Unmarshal(bytes, &page)
+	return svs, it.Err()
+}
+
+// ServersIter returns an iterator over the servers of all the users. The pages are fetched as they are needed.
+func (c *ApplicationCredentials) ServersIter(opts *ListOptions) *ServerIterator {
+	return c.ServersIterContext(context.Background(), opts)
+}
+
+// ServersIterContext is ServersIter with a context
+func (c *ApplicationCredentials) ServersIterContext(ctx context.Context, opts *ListOptions) *ServerIterator {
+	return &ServerIterator{pager: newPager(ctx, (*Credentials)(c), c.endpointURL("servers?include=allocations"), opts)}
+}
+
+// CreateServer creates a new server. If the server has a Deploy set the panel chooses its node and allocation.
+// Use CreateServerReturning to get the server as created by the panel.
+func (c *ApplicationCredentials) CreateServer(sv *ApplicationServer) error {
+	return c.CreateServerContext(context.Background(), sv)
+}
+
+// CreateServerContext is CreateServer with a context
+func (c *ApplicationCredentials) CreateServerContext(ctx context.Context, sv *ApplicationServer) (err error) {
+	_, err = c.CreateServerReturningContext(ctx, sv)
+	return
+}
+
+// CreateServerReturning is CreateServer, but returns the server as created by the panel, with its ID, UUID, node,
+// allocation and dates. The passed server is left untouched.
+func (c *ApplicationCredentials) CreateServerReturning(sv *ApplicationServer) (*ApplicationServer, error) {
+	return c.CreateServerReturningContext(context.Background(), sv)
+}
+
+// CreateServerReturningContext is CreateServerReturning with a context
+func (c *ApplicationCredentials) CreateServerReturningContext(ctx context.Context,
+	sv *ApplicationServer) (created *ApplicationServer, err error) {
+	rq, err := json.Marshal(sv.asJSONServerCreation())
 	if err != nil {
 		return
 	}
 
-	// Search for the remaining pages if present
-	pages, err := page.getAll(c.Token)
+	bytes, err := c.query(ctx, "servers", "POST", rq)
 	if err != nil {
 		return
 	}
 
-	for _, page := range pages {
-		svs = append(svs, page.asApplicationServers()...)
-	}
-
-	return
-}
-
-// CreateServer creates a new server
-func (c *ApplicationCredentials) CreateServer(sv *ApplicationServer) (err error) {
-	bytes, err := json.Marshal(sv.asJSONServerCreation())
-	if err != nil {
-		return err
+	var wrapper struct {
+		Server jsonServer `json:"attributes"`
 	}
 
-	_, err = c.query("servers", "POST", bytes)
+	err = json.Unmarshal(bytes, &wrapper)
 	if err != nil {
 		return
 	}
 
-	return nil
+	return wrapper.Server.asApplicationServer(), nil
 }
 
 // UpdateDetails modifies the server name, user, external id and description
-func (c *ApplicationCredentials) UpdateDetails(sv *ApplicationServer) (err error) {
+func (c *ApplicationCredentials) UpdateDetails(sv *ApplicationServer) error {
+	return c.UpdateDetailsContext(context.Background(), sv)
+}
+
+// UpdateDetailsContext is UpdateDetails with a context
+func (c *ApplicationCredentials) UpdateDetailsContext(ctx context.Context, sv *ApplicationServer) (err error) {
 	type details struct {
 		ExternalID  string `json:"external_id"`
 		Name        string `json:"name"`
@@ -420,7 +658,7 @@ func (c *ApplicationCredentials) UpdateDetails(sv *ApplicationServer) (err error
 		return err
 	}
 
-	_, err = c.query(fmt.Sprintf("servers/%d/details", sv.ID), "PATCH", bytes)
+	_, err = c.query(ctx, fmt.Sprintf("servers/%d/details", sv.ID), "PATCH", bytes)
 	if err != nil {
 		return
 	}
@@ -429,7 +667,12 @@ func (c *ApplicationCredentials) UpdateDetails(sv *ApplicationServer) (err error
 }
 
 // UpdateBuild modifies the server's limit and allocation configuration
-func (c *ApplicationCredentials) UpdateBuild(sv *ApplicationServer, addAlloc []int, removeAlloc []int) (err error) {
+func (c *ApplicationCredentials) UpdateBuild(sv *ApplicationServer, addAlloc []int, removeAlloc []int) error {
+	return c.UpdateBuildContext(context.Background(), sv, addAlloc, removeAlloc)
+}
+
+// UpdateBuildContext is UpdateBuild with a context
+func (c *ApplicationCredentials) UpdateBuildContext(ctx context.Context, sv *ApplicationServer, addAlloc []int, removeAlloc []int) (err error) {
 	type build struct {
 		Allocation        int     `json:"allocation,omitempty"`
 		OOM               bool    `json:"oom_disabled"`
@@ -458,7 +701,7 @@ func (c *ApplicationCredentials) UpdateBuild(sv *ApplicationServer, addAlloc []i
 		return err
 	}
 
-	_, err = c.query(fmt.Sprintf("servers/%d/build", sv.ID), "PATCH", bytes)
+	_, err = c.query(ctx, fmt.Sprintf("servers/%d/build", sv.ID), "PATCH", bytes)
 	if err != nil {
 		return
 	}
@@ -467,28 +710,24 @@ func (c *ApplicationCredentials) UpdateBuild(sv *ApplicationServer, addAlloc []i
 }
 
 // UpdateStartup modifies the server's startup parameters, egg and image configuration
-func (c *ApplicationCredentials) UpdateStartup(sv *ApplicationServer) (err error) {
-	type startup struct {
-		Startup     string   `json:"startup"`
-		Environment []string `json:"environment"`
-		Egg         int      `json:"egg"`
-		Pack        int      `json:"pack"`
-		Image       string   `json:"image"`
-		SkipScripts bool     `json:"skip_scripts"`
-	}
+func (c *ApplicationCredentials) UpdateStartup(sv *ApplicationServer) error {
+	return c.UpdateStartupContext(context.Background(), sv)
+}
 
-	// Since Pterodactyl doesn't like to have a stable definition for it's parameters we need Environment
-	// as a slice of the env of the environmental variables and not as a map.
-	//
-	// A note on this approach: Map operations are NOT thread safe
-	env := make([]string, 0, len(sv.Container.Environment))
-	for k := range sv.Container.Environment {
-		env = append(env, k)
+// UpdateStartupContext is UpdateStartup with a context
+func (c *ApplicationCredentials) UpdateStartupContext(ctx context.Context, sv *ApplicationServer) (err error) {
+	type startup struct {
+		Startup     string            `json:"startup"`
+		Environment map[string]string `json:"environment"`
+		Egg         int               `json:"egg"`
+		Pack        int               `json:"pack"`
+		Image       string            `json:"image"`
+		SkipScripts bool              `json:"skip_scripts"`
 	}
 
 	su := startup{
 		Startup:     sv.Container.StartupCommand,
-		Environment: env,
+		Environment: sv.Container.Environment,
 		Egg:         sv.Egg,
 		Image:       sv.Container.Image,
 		Pack:        sv.Pack,
@@ -499,43 +738,73 @@ func (c *ApplicationCredentials) UpdateStartup(sv *ApplicationServer) (err error
 		return err
 	}
 
-	_, err = c.query(fmt.Sprintf("servers/%d/startup", sv.ID), "PATCH", bytes)
+	_, err = c.query(ctx, fmt.Sprintf("servers/%d/startup", sv.ID), "PATCH", bytes)
 	return
 }
 
 // SuspendServer marks a server as suspended
-func (c *ApplicationCredentials) SuspendServer(sid int) (err error) {
-	_, err = c.query(fmt.Sprintf("servers/%d/suspend", sid), "POST", nil)
+func (c *ApplicationCredentials) SuspendServer(sid int) error {
+	return c.SuspendServerContext(context.Background(), sid)
+}
+
+// SuspendServerContext is SuspendServer with a context
+func (c *ApplicationCredentials) SuspendServerContext(ctx context.Context, sid int) (err error) {
+	_, err = c.query(ctx, fmt.Sprintf("servers/%d/suspend", sid), "POST", nil)
 	return
 }
 
 // UnsuspendServer marks a server as active
-func (c *ApplicationCredentials) UnsuspendServer(sid int) (err error) {
-	_, err = c.query(fmt.Sprintf("servers/%d/unsuspend", sid), "POST", nil)
+func (c *ApplicationCredentials) UnsuspendServer(sid int) error {
+	return c.UnsuspendServerContext(context.Background(), sid)
+}
+
+// UnsuspendServerContext is UnsuspendServer with a context
+func (c *ApplicationCredentials) UnsuspendServerContext(ctx context.Context, sid int) (err error) {
+	_, err = c.query(ctx, fmt.Sprintf("servers/%d/unsuspend", sid), "POST", nil)
 	return
 }
 
 // RebuildServer starts a server rebuild
-func (c *ApplicationCredentials) RebuildServer(sid int) (err error) {
-	_, err = c.query(fmt.Sprintf("servers/%d/rebuild", sid), "POST", nil)
+func (c *ApplicationCredentials) RebuildServer(sid int) error {
+	return c.RebuildServerContext(context.Background(), sid)
+}
+
+// RebuildServerContext is RebuildServer with a context
+func (c *ApplicationCredentials) RebuildServerContext(ctx context.Context, sid int) (err error) {
+	_, err = c.query(ctx, fmt.Sprintf("servers/%d/rebuild", sid), "POST", nil)
 	return
 }
 
 // ReinstallServer marks a server for reinstallation
-func (c *ApplicationCredentials) ReinstallServer(sid int) (err error) {
-	_, err = c.query(fmt.Sprintf("servers/%d/reinstall", sid), "POST", nil)
+func (c *ApplicationCredentials) ReinstallServer(sid int) error {
+	return c.ReinstallServerContext(context.Background(), sid)
+}
+
+// ReinstallServerContext is ReinstallServer with a context
+func (c *ApplicationCredentials) ReinstallServerContext(ctx context.Context, sid int) (err error) {
+	_, err = c.query(ctx, fmt.Sprintf("servers/%d/reinstall", sid), "POST", nil)
 	return
 }
 
 // DeleteServer marks a server for deletion
-func (c *ApplicationCredentials) DeleteServer(sid int) (err error) {
-	_, err = c.query(fmt.Sprintf("servers/%d", sid), "DELETE", nil)
+func (c *ApplicationCredentials) DeleteServer(sid int) error {
+	return c.DeleteServerContext(context.Background(), sid)
+}
+
+// DeleteServerContext is DeleteServer with a context
+func (c *ApplicationCredentials) DeleteServerContext(ctx context.Context, sid int) (err error) {
+	_, err = c.query(ctx, fmt.Sprintf("servers/%d", sid), "DELETE", nil)
 	return
 }
 
 // ForceDeleteServer forcefully deletes a server. This is an ungraceful way to delete the server, and when
 // possible DeleteServer should be preferred.
-func (c *ApplicationCredentials) ForceDeleteServer(sid int) (err error) {
-	_, err = c.query(fmt.Sprintf("servers/%d/force", sid), "DELETE", nil)
+func (c *ApplicationCredentials) ForceDeleteServer(sid int) error {
+	return c.ForceDeleteServerContext(context.Background(), sid)
+}
+
+// ForceDeleteServerContext is ForceDeleteServer with a context
+func (c *ApplicationCredentials) ForceDeleteServerContext(ctx context.Context, sid int) (err error) {
+	_, err = c.query(ctx, fmt.Sprintf("servers/%d/force", sid), "DELETE", nil)
 	return
 }
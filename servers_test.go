@@ -1,6 +1,7 @@
 package fossil
 
 import (
+	"context"
 	"github.com/google/go-cmp/cmp"
 	"testing"
 	"time"
@@ -9,7 +10,7 @@ import (
 //***** Testing *****//
 
 func TestApplicationCredentials_GetServers(t *testing.T) {
-	query = func(url, token, method string, data []byte) ([]byte, error) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
 		expectURL := "https://example.com/api/application/servers?include=allocations"
 		if expectURL != url {
 			t.Errorf("Request url does not match expected: %s", url)
@@ -215,7 +216,7 @@ func TestApplicationCredentials_GetServers(t *testing.T) {
 }
 
 func TestApplicationCredentials_GetServer(t *testing.T) {
-	query = func(url, token, method string, data []byte) ([]byte, error) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
 		expectURL := "https://example.com/api/application/servers/2?include=allocations"
 		if expectURL != url {
 			t.Errorf("Request url does not match expected: %s", url)
@@ -322,7 +323,7 @@ func TestApplicationCredentials_GetServer(t *testing.T) {
 }
 
 func TestApplicationCredentials_GetServerExternal(t *testing.T) {
-	query = func(url, token, method string, data []byte) ([]byte, error) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
 		expectURL := "https://example.com/api/application/servers/external/cow_eater?include=allocations"
 		if expectURL != url {
 			t.Errorf("Request url does not match expected: %s", url)
@@ -429,25 +430,95 @@ func TestApplicationCredentials_GetServerExternal(t *testing.T) {
 }
 
 func TestApplicationCredentials_CreateServer(t *testing.T) {
-	query = func(url, token, method string, data []byte) ([]byte, error) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
 		expectURL := "https://example.com/api/application/servers"
 		if expectURL != url {
 			t.Errorf("Request url does not match expected: %s", url)
 		}
 
-		return nil, nil
+		res := `{
+		  "object": "server",
+		  "attributes": {
+			"id": 7,
+			"external_id": null,
+			"uuid": "d557c19c-8b21-4456-a9e5-181beda429f4",
+			"identifier": "d557c19c",
+			"name": "Test Server",
+			"node": 1,
+			"allocation": 5
+		  }
+		}`
+
+		return []byte(res), nil
 	}
 
 	a := NewApplication("https://example.com", "")
 
-	err := a.CreateServer(&ApplicationServer{})
+	sv := &ApplicationServer{Name: "Test Server", User: 1}
+	got, err := a.CreateServerReturning(sv)
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	if got.ID != 7 || got.UUID != "d557c19c-8b21-4456-a9e5-181beda429f4" || got.Allocation != 5 {
+		t.Errorf("Unexpected response: %+v", got)
+	}
+
+	if sv.ID != 0 || sv.User != 1 {
+		t.Errorf("The passed server was modified: %+v", sv)
+	}
+
+	err = a.CreateServer(sv)
 	if err != nil {
 		t.Errorf("Error: %s", err.Error())
 	}
 }
 
+func TestApplicationCredentials_CreateServerDeploy(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectBody := `{"external_id":"","name":"Deployed","description":"","limits":{"memory":512,"swap":0,` +
+			`"disk":1024,"io":500,"cpu":100},"startup":"","environment":null,"skip_scripts":false,` +
+			`"feature_limits":{"databases":0,"allocations":0},"user":1,"node":0,"allocation":{},` +
+			`"deploy":{"locations":[1,2],"dedicated_ip":false,"port_range":["25565-25600"]},"nest":1,"egg":4}`
+		if expectBody != string(data) {
+			t.Errorf("Request data does not match expected: %s", string(data))
+		}
+
+		return []byte(`{"object":"server","attributes":{"id":8}}`), nil
+	}
+
+	a := NewApplication("https://example.com", "")
+
+	sv := &ApplicationServer{
+		Name:       "Deployed",
+		User:       1,
+		Nest:       1,
+		Egg:        4,
+		Allocation: 9000, // Ignored when deploying
+		Limits: Limits{
+			Memory: 512,
+			Disk:   1024,
+			IO:     500,
+			CPU:    100,
+		},
+		Deploy: &Deploy{
+			Locations: []int{1, 2},
+			PortRange: []string{"25565-25600"},
+		},
+	}
+
+	err := a.CreateServer(sv)
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	if sv.Name != "Deployed" || sv.Deploy == nil {
+		t.Errorf("The passed server was modified: %+v", sv)
+	}
+}
+
 func TestApplicationCredentials_UpdateDetails(t *testing.T) {
-	query = func(url, token, method string, data []byte) ([]byte, error) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
 		expectURL := "https://example.com/api/application/servers/1/details"
 		if expectURL != url {
 			t.Errorf("Request url does not match expected: %s", url)
@@ -480,7 +551,7 @@ func TestApplicationCredentials_UpdateDetails(t *testing.T) {
 }
 
 func TestApplicationCredentials_UpdateBuild(t *testing.T) {
-	query = func(url, token, method string, data []byte) ([]byte, error) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
 		expectURL := "https://example.com/api/application/servers/1/build"
 		if expectURL != url {
 			t.Errorf("Request url does not match expected: %s", url)
@@ -522,16 +593,15 @@ func TestApplicationCredentials_UpdateBuild(t *testing.T) {
 }
 
 func TestApplicationCredentials_UpdateStartup(t *testing.T) {
-	query = func(url, token, method string, data []byte) ([]byte, error) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
 		expectURL := "https://example.com/api/application/servers/1/startup"
 		if expectURL != url {
 			t.Errorf("Request url does not match expected: %s", url)
 		}
 
-		// Modified a bit from the original API example. The Map object in the request originally had 2 items
-		// but would fail the test if they swapped order.
+		// The environment keys are sorted when encoded, so the order is stable
 		expectBody := `{"startup":"java -Xms128M -Xmx1024M -jar paper.jar",` +
-			`"environment":["SERVER_JARFILE"],"egg":1,` +
+			`"environment":{"SERVER_JARFILE":"paper.jar","VANILLA_VERSION":"latest"},"egg":1,` +
 			`"pack":4,"image":"quay.io/pterodactyl/core:java","skip_scripts":false}`
 
 		if expectBody != string(data) {
@@ -548,7 +618,7 @@ func TestApplicationCredentials_UpdateStartup(t *testing.T) {
 		Container: Container{
 			StartupCommand: "java -Xms128M -Xmx1024M -jar paper.jar",
 			Image:          "quay.io/pterodactyl/core:java",
-			Environment:    map[string]string{"SERVER_JARFILE": ""},
+			Environment:    map[string]string{"VANILLA_VERSION": "latest", "SERVER_JARFILE": "paper.jar"},
 		},
 		Egg:  1,
 		Pack: 4,
@@ -561,7 +631,7 @@ func TestApplicationCredentials_UpdateStartup(t *testing.T) {
 }
 
 func TestApplicationCredentials_SuspendServer(t *testing.T) {
-	query = func(url, token, method string, data []byte) ([]byte, error) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
 		expectURL := "https://example.com/api/application/servers/1/suspend"
 		if expectURL != url {
 			t.Errorf("Request url does not match expected: %s", url)
@@ -579,7 +649,7 @@ func TestApplicationCredentials_SuspendServer(t *testing.T) {
 }
 
 func TestApplicationCredentials_UnsuspendServer(t *testing.T) {
-	query = func(url, token, method string, data []byte) ([]byte, error) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
 		expectURL := "https://example.com/api/application/servers/1/unsuspend"
 		if expectURL != url {
 			t.Errorf("Request url does not match expected: %s", url)
@@ -597,7 +667,7 @@ func TestApplicationCredentials_UnsuspendServer(t *testing.T) {
 }
 
 func TestApplicationCredentials_ReinstallServer(t *testing.T) {
-	query = func(url, token, method string, data []byte) ([]byte, error) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
 		expectURL := "https://example.com/api/application/servers/1/reinstall"
 		if expectURL != url {
 			t.Errorf("Request url does not match expected: %s", url)
@@ -615,7 +685,7 @@ func TestApplicationCredentials_ReinstallServer(t *testing.T) {
 }
 
 func TestApplicationCredentials_RebuildServer(t *testing.T) {
-	query = func(url, token, method string, data []byte) ([]byte, error) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
 		expectURL := "https://example.com/api/application/servers/1/rebuild"
 		if expectURL != url {
 			t.Errorf("Request url does not match expected: %s", url)
@@ -633,7 +703,7 @@ func TestApplicationCredentials_RebuildServer(t *testing.T) {
 }
 
 func TestApplicationCredentials_DeleteServer(t *testing.T) {
-	query = func(url, token, method string, data []byte) ([]byte, error) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
 		expectURL := "https://example.com/api/application/servers/1"
 		if expectURL != url {
 			t.Errorf("Request url does not match expected: %s", url)
@@ -651,7 +721,7 @@ func TestApplicationCredentials_DeleteServer(t *testing.T) {
 }
 
 func TestApplicationCredentials_ForceDeleteServer(t *testing.T) {
-	query = func(url, token, method string, data []byte) ([]byte, error) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
 		expectURL := "https://example.com/api/application/servers/1/force"
 		if expectURL != url {
 			t.Errorf("Request url does not match expected: %s", url)
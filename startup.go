@@ -0,0 +1,148 @@
+package fossil
+
+import (
+	"context"
+	"encoding/json"
+)
+
+//***** Structures *****//
+
+// Startup holds the startup configuration of a server, as seen by its users
+type Startup struct {
+	Command      string            // Startup command, with the variables replaced by their values
+	RawCommand   string            // Startup command, as defined by the egg
+	DockerImages map[string]string // Images allowed by the egg, keyed by their display name
+	Variables    []*StartupVariable
+}
+
+// StartupVariable is an egg variable of a server. Only editable variables can be modified by the users.
+type StartupVariable struct {
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	EnvVariable  string `json:"env_variable"`
+	DefaultValue string `json:"default_value"`
+	ServerValue  string `json:"server_value"`
+	IsEditable   bool   `json:"is_editable"`
+	Rules        string `json:"rules"` // Laravel validation rules, like "required|string|max:20"
+}
+
+// jsonStartup contains the startup variables and the command.
+// It's used as the target struct in the unmarshalling of API responses.
+type jsonStartup struct {
+	Data []struct {
+		Variable *StartupVariable `json:"attributes"`
+	} `json:"data"`
+	Meta struct {
+		StartupCommand    string          `json:"startup_command"`
+		RawStartupCommand string          `json:"raw_startup_command"`
+		DockerImages      json.RawMessage `json:"docker_images"`
+	} `json:"meta"`
+}
+
+//***** Converters *****//
+
+// asStartup parses a jsonStartup into a *Startup
+func (s *jsonStartup) asStartup() (*Startup, error) {
+	su := &Startup{
+		Command:      s.Meta.StartupCommand,
+		RawCommand:   s.Meta.RawStartupCommand,
+		DockerImages: make(map[string]string),
+	}
+
+	for _, d := range s.Data {
+		su.Variables = append(su.Variables, d.Variable)
+	}
+
+	if len(s.Meta.DockerImages) == 0 || string(s.Meta.DockerImages) == "null" {
+		return su, nil
+	}
+
+	// Older panels list the images without display names
+	var images []string
+	if json.Unmarshal(s.Meta.DockerImages, &images) == nil {
+		for _, img := range images {
+			su.DockerImages[img] = img
+		}
+
+		return su, nil
+	}
+
+	err := json.Unmarshal(s.Meta.DockerImages, &su.DockerImages)
+	if err != nil {
+		return nil, err
+	}
+
+	return su, nil
+}
+
+//***** Helpers *****//
+
+// Variable returns the variable with the given environment key, or nil if there's none
+func (s *Startup) Variable(key string) *StartupVariable {
+	for _, v := range s.Variables {
+		if v.EnvVariable == key {
+			return v
+		}
+	}
+
+	return nil
+}
+
+//***** Requests *****//
+
+// GetStartup fetches the startup command, the allowed Docker images and the variables of a server
+func (c *ClientCredentials) GetStartup(id string) (*Startup, error) {
+	return c.GetStartupContext(context.Background(), id)
+}
+
+// GetStartupContext is GetStartup with a context
+func (c *ClientCredentials) GetStartupContext(ctx context.Context, id string) (*Startup, error) {
+	bytes, err := c.query(ctx, "servers/"+id+"/startup", "GET", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var js jsonStartup
+	err = json.Unmarshal(bytes, &js)
+	if err != nil {
+		return nil, err
+	}
+
+	return js.asStartup()
+}
+
+// UpdateStartupVariable sets the value of a startup variable, and returns the updated variable. Values not
+// complying with the variable's rules are rejected with an APIError, see IsValidationError.
+func (c *ClientCredentials) UpdateStartupVariable(id, key, value string) (*StartupVariable, error) {
+	return c.UpdateStartupVariableContext(context.Background(), id, key, value)
+}
+
+// UpdateStartupVariableContext is UpdateStartupVariable with a context
+func (c *ClientCredentials) UpdateStartupVariableContext(ctx context.Context, id, key,
+	value string) (variable *StartupVariable, err error) {
+	type wrapper struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}
+
+	rq, err := json.Marshal(wrapper{Key: key, Value: value})
+	if err != nil {
+		return
+	}
+
+	bytes, err := c.query(ctx, "servers/"+id+"/startup/variable", "PUT", rq)
+	if err != nil {
+		return
+	}
+
+	var fWrapper struct {
+		Variable *StartupVariable `json:"attributes"`
+	}
+
+	err = json.Unmarshal(bytes, &fWrapper)
+	if err != nil {
+		return
+	}
+
+	return fWrapper.Variable, nil
+}
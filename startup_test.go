@@ -0,0 +1,133 @@
+package fossil
+
+import (
+	"context"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+)
+
+//***** Testing *****//
+
+func TestClientCredentials_GetStartup(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/startup"
+		if expectURL != url {
+			t.Errorf("Request url does not match expected: %s", url)
+		}
+
+		res := `{
+		  "object": "list",
+		  "data": [
+			{
+			  "object": "egg_variable",
+			  "attributes": {
+				"name": "Server Jar File",
+				"description": "The name of the server jarfile to run the server with.",
+				"env_variable": "SERVER_JARFILE",
+				"default_value": "server.jar",
+				"server_value": "paper.jar",
+				"is_editable": true,
+				"rules": "required|regex:/^([\\w\\d._-]+)(\\.jar)$/"
+			  }
+			}
+		  ],
+		  "meta": {
+			"startup_command": "java -Xms128M -Xmx1024M -jar paper.jar",
+			"raw_startup_command": "java -Xms128M -Xmx{{SERVER_MEMORY}}M -jar {{SERVER_JARFILE}}",
+			"docker_images": {
+			  "Java 17": "ghcr.io/pterodactyl/yolks:java_17"
+			}
+		  }
+		}`
+
+		return []byte(res), nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	got, err := c.GetStartup("1a7ce997")
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	expect := &Startup{
+		Command:      "java -Xms128M -Xmx1024M -jar paper.jar",
+		RawCommand:   "java -Xms128M -Xmx{{SERVER_MEMORY}}M -jar {{SERVER_JARFILE}}",
+		DockerImages: map[string]string{"Java 17": "ghcr.io/pterodactyl/yolks:java_17"},
+		Variables: []*StartupVariable{
+			{
+				Name:         "Server Jar File",
+				Description:  "The name of the server jarfile to run the server with.",
+				EnvVariable:  "SERVER_JARFILE",
+				DefaultValue: "server.jar",
+				ServerValue:  "paper.jar",
+				IsEditable:   true,
+				Rules:        `required|regex:/^([\w\d._-]+)(\.jar)$/`,
+			},
+		},
+	}
+
+	if !cmp.Equal(got, expect) {
+		t.Errorf("Unexpected response: %s", cmp.Diff(got, expect))
+	}
+
+	if got.Variable("SERVER_JARFILE") != got.Variables[0] || got.Variable("MISSING") != nil {
+		t.Error("Unexpected variable lookup")
+	}
+}
+
+func TestClientCredentials_GetStartup_ImageList(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		return []byte(`{"object":"list","data":[],"meta":{"docker_images":["quay.io/pterodactyl/core:java"]}}`), nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	got, err := c.GetStartup("1a7ce997")
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	expect := map[string]string{"quay.io/pterodactyl/core:java": "quay.io/pterodactyl/core:java"}
+	if !cmp.Equal(got.DockerImages, expect) {
+		t.Errorf("Unexpected response: %s", cmp.Diff(got.DockerImages, expect))
+	}
+}
+
+func TestClientCredentials_UpdateStartupVariable(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/startup/variable"
+		if expectURL != url || method != "PUT" {
+			t.Errorf("Request url does not match expected: %s %s", method, url)
+		}
+
+		if string(data) == `{"key":"SERVER_JARFILE","value":"paper"}` {
+			return nil, &APIError{StatusCode: 422, Errors: []*ErrorDetail{{Code: "ValidationException",
+				Status: "422", Detail: "The server jar file format is invalid."}}}
+		}
+
+		expectBody := `{"key":"SERVER_JARFILE","value":"purpur.jar"}`
+		if expectBody != string(data) {
+			t.Errorf("Request body does not match expected: %s", data)
+		}
+
+		return []byte(`{"object":"egg_variable","attributes":{"env_variable":"SERVER_JARFILE",
+			"server_value":"purpur.jar","is_editable":true}}`), nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	got, err := c.UpdateStartupVariable("1a7ce997", "SERVER_JARFILE", "purpur.jar")
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	if got.ServerValue != "purpur.jar" {
+		t.Errorf("Unexpected response: %+v", got)
+	}
+
+	_, err = c.UpdateStartupVariable("1a7ce997", "SERVER_JARFILE", "paper")
+	if !IsValidationError(err) {
+		t.Errorf("Expected a validation error, got: %v", err)
+	}
+}
@@ -0,0 +1,294 @@
+package fossil
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Permission is a single access right of a subuser on a server
+type Permission string
+
+// Subuser permissions
+const (
+	PermissionWebsocketConnect Permission = "websocket.connect"
+
+	PermissionControlConsole Permission = "control.console"
+	PermissionControlStart   Permission = "control.start"
+	PermissionControlStop    Permission = "control.stop"
+	PermissionControlRestart Permission = "control.restart"
+
+	PermissionUserCreate Permission = "user.create"
+	PermissionUserRead   Permission = "user.read"
+	PermissionUserUpdate Permission = "user.update"
+	PermissionUserDelete Permission = "user.delete"
+
+	PermissionFileCreate      Permission = "file.create"
+	PermissionFileRead        Permission = "file.read"
+	PermissionFileReadContent Permission = "file.read-content"
+	PermissionFileUpdate      Permission = "file.update"
+	PermissionFileDelete      Permission = "file.delete"
+	PermissionFileArchive     Permission = "file.archive"
+	PermissionFileSFTP        Permission = "file.sftp"
+
+	PermissionBackupCreate   Permission = "backup.create"
+	PermissionBackupRead     Permission = "backup.read"
+	PermissionBackupDelete   Permission = "backup.delete"
+	PermissionBackupDownload Permission = "backup.download"
+	PermissionBackupRestore  Permission = "backup.restore"
+
+	PermissionAllocationRead   Permission = "allocation.read"
+	PermissionAllocationCreate Permission = "allocation.create"
+	PermissionAllocationUpdate Permission = "allocation.update"
+	PermissionAllocationDelete Permission = "allocation.delete"
+
+	PermissionStartupRead        Permission = "startup.read"
+	PermissionStartupUpdate      Permission = "startup.update"
+	PermissionStartupDockerImage Permission = "startup.docker-image"
+
+	PermissionDatabaseCreate       Permission = "database.create"
+	PermissionDatabaseRead         Permission = "database.read"
+	PermissionDatabaseUpdate       Permission = "database.update"
+	PermissionDatabaseDelete       Permission = "database.delete"
+	PermissionDatabaseViewPassword Permission = "database.view_password"
+
+	PermissionScheduleCreate Permission = "schedule.create"
+	PermissionScheduleRead   Permission = "schedule.read"
+	PermissionScheduleUpdate Permission = "schedule.update"
+	PermissionScheduleDelete Permission = "schedule.delete"
+
+	PermissionSettingsRename    Permission = "settings.rename"
+	PermissionSettingsReinstall Permission = "settings.reinstall"
+
+	PermissionActivityRead Permission = "activity.read"
+)
+
+//***** Structures *****//
+
+// Subuser is an account given limited access to a server it doesn't own
+type Subuser struct {
+	UUID                    string       `json:"uuid"`
+	Username                string       `json:"username"`
+	Email                   string       `json:"email"`
+	Image                   string       `json:"image"`
+	TwoFactorAuthentication bool         `json:"2fa_enabled"`
+	Permissions             []Permission `json:"permissions"`
+	CreatedAt               time.Time    `json:"created_at"`
+}
+
+// PermissionCatalogue holds the permissions known by the panel, grouped by their prefix (control, file...)
+type PermissionCatalogue map[string]*PermissionGroup
+
+// PermissionGroup is a set of related permissions. The keys map each permission suffix to its description.
+type PermissionGroup struct {
+	Description string            `json:"description"`
+	Keys        map[string]string `json:"keys"`
+}
+
+//***** Helpers *****//
+
+// Has reports whether the panel knows the permission
+func (pc PermissionCatalogue) Has(p Permission) bool {
+	parts := strings.SplitN(string(p), ".", 2)
+	if len(parts) != 2 {
+		return false
+	}
+
+	group, ok := pc[parts[0]]
+	if !ok {
+		return false
+	}
+
+	_, ok = group.Keys[parts[1]]
+	return ok
+}
+
+// Validate returns an error listing the permissions unknown to the panel, if any
+func (pc PermissionCatalogue) Validate(perms ...Permission) error {
+	var unknown []string
+	for _, p := range perms {
+		if !pc.Has(p) {
+			unknown = append(unknown, string(p))
+		}
+	}
+
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	sort.Strings(unknown)
+	return errors.New("unknown permissions: " + strings.Join(unknown, ", "))
+}
+
+// Permissions returns all the permissions of the catalogue, sorted
+func (pc PermissionCatalogue) Permissions() (perms []Permission) {
+	for name, group := range pc {
+		for key := range group.Keys {
+			perms = append(perms, Permission(name+"."+key))
+		}
+	}
+
+	sort.Slice(perms, func(i, j int) bool {
+		return perms[i] < perms[j]
+	})
+
+	return perms
+}
+
+//***** String *****//
+
+func (s *Subuser) String() string {
+	return s.Username
+}
+
+//***** Requests *****//
+
+// ListSubusers fetches all the subusers of a server
+func (c *ClientCredentials) ListSubusers(id string) ([]*Subuser, error) {
+	return c.ListSubusersContext(context.Background(), id)
+}
+
+// ListSubusersContext is ListSubusers with a context
+func (c *ClientCredentials) ListSubusersContext(ctx context.Context, id string) (subusers []*Subuser, err error) {
+	bytes, err := c.query(ctx, "servers/"+id+"/users", "GET", nil)
+	if err != nil {
+		return
+	}
+
+	var wrapper struct {
+		Data []struct {
+			Subuser *Subuser `json:"attributes"`
+		} `json:"data"`
+	}
+
+	err = json.Unmarshal(bytes, &wrapper)
+	if err != nil {
+		return
+	}
+
+	for _, d := range wrapper.Data {
+		subusers = append(subusers, d.Subuser)
+	}
+
+	return subusers, nil
+}
+
+// GetSubuser fetches a subuser of a server by its UUID
+func (c *ClientCredentials) GetSubuser(id, uuid string) (*Subuser, error) {
+	return c.GetSubuserContext(context.Background(), id, uuid)
+}
+
+// GetSubuserContext is GetSubuser with a context
+func (c *ClientCredentials) GetSubuserContext(ctx context.Context, id, uuid string) (*Subuser, error) {
+	bytes, err := c.query(ctx, "servers/"+id+"/users/"+uuid, "GET", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeSubuser(bytes)
+}
+
+// CreateSubuser gives the account with the given email access to a server. If there's no such account the panel
+// creates one and sends an invitation.
+func (c *ClientCredentials) CreateSubuser(id, email string, perms ...Permission) (*Subuser, error) {
+	return c.CreateSubuserContext(context.Background(), id, email, perms...)
+}
+
+// CreateSubuserContext is CreateSubuser with a context
+func (c *ClientCredentials) CreateSubuserContext(ctx context.Context, id, email string,
+	perms ...Permission) (subuser *Subuser, err error) {
+	type wrapper struct {
+		Email       string       `json:"email"`
+		Permissions []Permission `json:"permissions"`
+	}
+
+	rq, err := json.Marshal(wrapper{Email: email, Permissions: perms})
+	if err != nil {
+		return
+	}
+
+	bytes, err := c.query(ctx, "servers/"+id+"/users", "POST", rq)
+	if err != nil {
+		return
+	}
+
+	return decodeSubuser(bytes)
+}
+
+// UpdateSubuser replaces the permissions of the subuser with the ones of the passed object. The other
+// parameters can't be modified.
+func (c *ClientCredentials) UpdateSubuser(id string, s *Subuser) error {
+	return c.UpdateSubuserContext(context.Background(), id, s)
+}
+
+// UpdateSubuserContext is UpdateSubuser with a context
+func (c *ClientCredentials) UpdateSubuserContext(ctx context.Context, id string, s *Subuser) (err error) {
+	type wrapper struct {
+		Permissions []Permission `json:"permissions"`
+	}
+
+	bytes, err := json.Marshal(wrapper{Permissions: s.Permissions})
+	if err != nil {
+		return
+	}
+
+	_, err = c.query(ctx, "servers/"+id+"/users/"+s.UUID, "POST", bytes)
+	return
+}
+
+// DeleteSubuser revokes the access of a subuser to a server
+func (c *ClientCredentials) DeleteSubuser(id, uuid string) error {
+	return c.DeleteSubuserContext(context.Background(), id, uuid)
+}
+
+// DeleteSubuserContext is DeleteSubuser with a context
+func (c *ClientCredentials) DeleteSubuserContext(ctx context.Context, id, uuid string) (err error) {
+	_, err = c.query(ctx, "servers/"+id+"/users/"+uuid, "DELETE", nil)
+	return
+}
+
+// GetPermissions fetches the catalogue of the permissions known by the panel. It can be used to validate
+// permissions before granting them.
+func (c *ClientCredentials) GetPermissions() (PermissionCatalogue, error) {
+	return c.GetPermissionsContext(context.Background())
+}
+
+// GetPermissionsContext is GetPermissions with a context
+func (c *ClientCredentials) GetPermissionsContext(ctx context.Context) (catalogue PermissionCatalogue, err error) {
+	bytes, err := c.query(ctx, "permissions", "GET", nil)
+	if err != nil {
+		return
+	}
+
+	var wrapper struct {
+		Attributes struct {
+			Permissions PermissionCatalogue `json:"permissions"`
+		} `json:"attributes"`
+	}
+
+	err = json.Unmarshal(bytes, &wrapper)
+	if err != nil {
+		return
+	}
+
+	return wrapper.Attributes.Permissions, nil
+}
+
+//***** Converters *****//
+
+// decodeSubuser parses a single subuser response
+func decodeSubuser(bytes []byte) (*Subuser, error) {
+	var wrapper struct {
+		Subuser *Subuser `json:"attributes"`
+	}
+
+	err := json.Unmarshal(bytes, &wrapper)
+	if err != nil {
+		return nil, err
+	}
+
+	return wrapper.Subuser, nil
+}
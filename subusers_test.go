@@ -0,0 +1,207 @@
+package fossil
+
+import (
+	"context"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+	"time"
+)
+
+//***** Testing *****//
+
+func TestClientCredentials_ListSubusers(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/users"
+		if expectURL != url {
+			t.Errorf("Request url does not match expected: %s", url)
+		}
+
+		return []byte(`{"object":"list","data":[{
+		  "object": "server_subuser",
+		  "attributes": {
+			"uuid": "60a7aec3-e17d-4aa9-abb3-56d944d204b4",
+			"username": "support",
+			"email": "support@example.com",
+			"image": "https://gravatar.com/avatar/3bb5e8e1e8a2aa53c08a1a5e2b6b8e3e",
+			"2fa_enabled": true,
+			"created_at": "2020-11-02T12:21:08+00:00",
+			"permissions": ["control.console", "file.read", "websocket.connect"]
+		  }
+		}]}`), nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	got, err := c.ListSubusers("1a7ce997")
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	created, _ := time.Parse(time.RFC3339, "2020-11-02T12:21:08+00:00")
+	expect := []*Subuser{
+		{
+			UUID:                    "60a7aec3-e17d-4aa9-abb3-56d944d204b4",
+			Username:                "support",
+			Email:                   "support@example.com",
+			Image:                   "https://gravatar.com/avatar/3bb5e8e1e8a2aa53c08a1a5e2b6b8e3e",
+			TwoFactorAuthentication: true,
+			Permissions:             []Permission{PermissionControlConsole, PermissionFileRead, PermissionWebsocketConnect},
+			CreatedAt:               created,
+		},
+	}
+
+	if !cmp.Equal(got, expect) {
+		t.Errorf("Unexpected response: %s", cmp.Diff(got, expect))
+	}
+}
+
+func TestClientCredentials_CreateSubuser(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/users"
+		if expectURL != url || method != "POST" {
+			t.Errorf("Request url does not match expected: %s %s", method, url)
+		}
+
+		expectBody := `{"email":"support@example.com","permissions":["control.console","file.read"]}`
+		if expectBody != string(data) {
+			t.Errorf("Request body does not match expected: %s", data)
+		}
+
+		res := `{
+		  "object": "server_subuser",
+		  "attributes": {
+			"uuid": "60a7aec3-e17d-4aa9-abb3-56d944d204b4",
+			"username": "support",
+			"email": "support@example.com",
+			"image": "https://gravatar.com/avatar/3bb5e8e1e8a2aa53c08a1a5e2b6b8e3e",
+			"2fa_enabled": true,
+			"created_at": "2020-11-02T12:21:08+00:00",
+			"permissions": ["control.console", "file.read", "websocket.connect"]
+		  }
+		}`
+
+		return []byte(res), nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	got, err := c.CreateSubuser("1a7ce997", "support@example.com", PermissionControlConsole, PermissionFileRead)
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	if got.UUID != "60a7aec3-e17d-4aa9-abb3-56d944d204b4" {
+		t.Errorf("Unexpected response: %+v", got)
+	}
+}
+
+func TestClientCredentials_UpdateSubuser(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/users/60a7aec3"
+		if expectURL != url || method != "POST" {
+			t.Errorf("Request url does not match expected: %s %s", method, url)
+		}
+
+		expectBody := `{"permissions":["backup.create","backup.read"]}`
+		if expectBody != string(data) {
+			t.Errorf("Request body does not match expected: %s", data)
+		}
+
+		res := `{
+		  "object": "server_subuser",
+		  "attributes": {
+			"uuid": "60a7aec3-e17d-4aa9-abb3-56d944d204b4",
+			"username": "support",
+			"email": "support@example.com",
+			"image": "https://gravatar.com/avatar/3bb5e8e1e8a2aa53c08a1a5e2b6b8e3e",
+			"2fa_enabled": true,
+			"created_at": "2020-11-02T12:21:08+00:00",
+			"permissions": ["control.console", "file.read", "websocket.connect"]
+		  }
+		}`
+
+		return []byte(res), nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	err := c.UpdateSubuser("1a7ce997", &Subuser{
+		UUID:        "60a7aec3",
+		Permissions: []Permission{PermissionBackupCreate, PermissionBackupRead},
+	})
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+}
+
+func TestClientCredentials_DeleteSubuser(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/users/60a7aec3"
+		if expectURL != url || method != "DELETE" {
+			t.Errorf("Request url does not match expected: %s %s", method, url)
+		}
+
+		return nil, nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	err := c.DeleteSubuser("1a7ce997", "60a7aec3")
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+}
+
+func TestClientCredentials_GetPermissions(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/permissions"
+		if expectURL != url {
+			t.Errorf("Request url does not match expected: %s", url)
+		}
+
+		res := `{
+		  "object": "system_permissions",
+		  "attributes": {
+			"permissions": {
+			  "control": {
+				"description": "Permissions that control a user's ability to control the power state of a server.",
+				"keys": {
+				  "console": "Allows a user to send commands to the server instance via the console.",
+				  "start": "Allows a user to start the server if it is stopped."
+				}
+			  },
+			  "file": {
+				"description": "Permissions that control a user's ability to modify the filesystem for this server.",
+				"keys": {
+				  "read": "Allows a user to view the contents of a directory."
+				}
+			  }
+			}
+		  }
+		}`
+
+		return []byte(res), nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	got, err := c.GetPermissions()
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	expect := []Permission{PermissionControlConsole, PermissionControlStart, PermissionFileRead}
+	if !cmp.Equal(got.Permissions(), expect) {
+		t.Errorf("Unexpected response: %s", cmp.Diff(got.Permissions(), expect))
+	}
+
+	err = got.Validate(PermissionControlConsole, PermissionFileRead)
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	err = got.Validate(PermissionFileRead, "file.write", "console")
+	if err == nil || err.Error() != "unknown permissions: console, file.write" {
+		t.Errorf("Unexpected validation: %v", err)
+	}
+}
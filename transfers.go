@@ -0,0 +1,243 @@
+package fossil
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+//***** Structures *****//
+
+// ProgressFunc is called as a transfer advances with the amount of bytes transferred so far and the total size, or
+// -1 if the total is unknown
+type ProgressFunc func(transferred, total int64)
+
+// progressReader reports the bytes read through it
+type progressReader struct {
+	r        io.Reader
+	total    int64
+	read     int64
+	progress ProgressFunc
+}
+
+// progressWriter reports the bytes written through it
+type progressWriter struct {
+	w        io.Writer
+	total    int64
+	written  int64
+	progress ProgressFunc
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.read += int64(n)
+		p.progress(p.read, p.total)
+	}
+
+	return n, err
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	if n > 0 {
+		p.written += int64(n)
+		p.progress(p.written, p.total)
+	}
+
+	return n, err
+}
+
+//***** Helpers *****//
+
+// readerSize guesses the size of the data behind a reader, returning -1 if it can't be known
+func readerSize(r io.Reader) int64 {
+	switch v := r.(type) {
+	case interface{ Len() int }:
+		return int64(v.Len())
+	case interface{ Stat() (os.FileInfo, error) }:
+		fi, err := v.Stat()
+		if err == nil && fi.Mode().IsRegular() {
+			return fi.Size()
+		}
+	}
+
+	return -1
+}
+
+// signedURL fetches a signed URL given by the panel at the endpoint
+func (c *ClientCredentials) signedURL(ctx context.Context, endpoint string) (string, error) {
+	bytes, err := c.query(ctx, endpoint, "GET", nil)
+	if err != nil {
+		return "", err
+	}
+
+	var wrapper struct {
+		Signed struct {
+			URL string `json:"url"`
+		} `json:"attributes"`
+	}
+
+	err = json.Unmarshal(bytes, &wrapper)
+	if err != nil {
+		return "", err
+	}
+
+	return wrapper.Signed.URL, nil
+}
+
+// transferClient returns a copy of the HTTP client without a timeout, as streaming a large file can take longer
+// than any sensible time limit for a request. Transfers are only bounded by their context.
+func (c *ClientCredentials) transferClient() *http.Client {
+	client := *(*Credentials)(c).client()
+	client.Timeout = 0
+
+	return &client
+}
+
+//***** Requests *****//
+
+// GetUploadURL fetches a signed URL to upload files to the server. The URL is only valid for a short time.
+func (c *ClientCredentials) GetUploadURL(id string) (string, error) {
+	return c.GetUploadURLContext(context.Background(), id)
+}
+
+// GetUploadURLContext is GetUploadURL with a context
+func (c *ClientCredentials) GetUploadURLContext(ctx context.Context, id string) (string, error) {
+	return c.signedURL(ctx, "servers/"+id+"/files/upload")
+}
+
+// GetDownloadURL fetches a signed URL to download a file of the server. The URL is only valid for a short time.
+func (c *ClientCredentials) GetDownloadURL(id, file string) (string, error) {
+	return c.GetDownloadURLContext(context.Background(), id, file)
+}
+
+// GetDownloadURLContext is GetDownloadURL with a context
+func (c *ClientCredentials) GetDownloadURLContext(ctx context.Context, id, file string) (string, error) {
+	return c.signedURL(ctx, "servers/"+id+"/files/download?file="+url.QueryEscape(file))
+}
+
+// UploadFile streams the contents of r into a file called name inside directory, without buffering it in memory.
+// The progress function is optional. The timeout set with WithTimeout doesn't apply, use UploadFileContext to
+// limit the upload.
+func (c *ClientCredentials) UploadFile(id, directory, name string, r io.Reader, progress ProgressFunc) error {
+	return c.UploadFileContext(context.Background(), id, directory, name, r, progress)
+}
+
+// UploadFileContext is UploadFile with a context
+func (c *ClientCredentials) UploadFileContext(ctx context.Context, id, directory, name string, r io.Reader,
+	progress ProgressFunc) error {
+	target, err := c.GetUploadURLContext(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	u, err := url.Parse(target)
+	if err != nil {
+		return err
+	}
+
+	q := u.Query()
+	q.Set("directory", directory)
+	u.RawQuery = q.Encode()
+
+	if progress != nil {
+		r = &progressReader{r: r, total: readerSize(r), progress: progress}
+	}
+
+	// The multipart body is written as it's sent
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	go func() {
+		part, err := mw.CreateFormFile("files", name)
+		if err == nil {
+			_, err = io.Copy(part, r)
+		}
+
+		if err == nil {
+			err = mw.Close()
+		}
+
+		_ = pw.CloseWithError(err)
+	}()
+
+	rq, err := http.NewRequestWithContext(ctx, "POST", u.String(), pr)
+	if err != nil {
+		_ = pr.Close()
+		return err
+	}
+
+	rq.Header.Set("Content-Type", mw.FormDataContentType())
+	if c.userAgent != "" {
+		rq.Header.Set("User-Agent", c.userAgent)
+	}
+
+	rp, err := c.transferClient().Do(rq)
+	if err != nil {
+		_ = pr.Close()
+		return err
+	}
+
+	defer rp.Body.Close()
+
+	if rp.StatusCode < 200 || rp.StatusCode > 226 {
+		body, _ := ioutil.ReadAll(rp.Body)
+		return newAPIError(rp, body)
+	}
+
+	return nil
+}
+
+// DownloadFile streams a file of the server into w, without buffering it in memory, and returns the amount of
+// bytes written. The progress function is optional. The timeout set with WithTimeout doesn't apply, use
+// DownloadFileContext to limit the download.
+func (c *ClientCredentials) DownloadFile(id, file string, w io.Writer, progress ProgressFunc) (int64, error) {
+	return c.DownloadFileContext(context.Background(), id, file, w, progress)
+}
+
+// DownloadFileContext is DownloadFile with a context
+func (c *ClientCredentials) DownloadFileContext(ctx context.Context, id, file string, w io.Writer,
+	progress ProgressFunc) (int64, error) {
+	target, err := c.GetDownloadURLContext(ctx, id, file)
+	if err != nil {
+		return 0, err
+	}
+
+	return c.download(ctx, target, w, progress)
+}
+
+// download streams the contents at the signed URL into w
+func (c *ClientCredentials) download(ctx context.Context, target string, w io.Writer,
+	progress ProgressFunc) (int64, error) {
+	rq, err := http.NewRequestWithContext(ctx, "GET", target, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	if c.userAgent != "" {
+		rq.Header.Set("User-Agent", c.userAgent)
+	}
+
+	rp, err := c.transferClient().Do(rq)
+	if err != nil {
+		return 0, err
+	}
+
+	defer rp.Body.Close()
+
+	if rp.StatusCode < 200 || rp.StatusCode > 226 {
+		body, _ := ioutil.ReadAll(rp.Body)
+		return 0, newAPIError(rp, body)
+	}
+
+	if progress != nil {
+		w = &progressWriter{w: w, total: rp.ContentLength, progress: progress}
+	}
+
+	return io.Copy(w, rp.Body)
+}
@@ -0,0 +1,160 @@
+package fossil
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+//***** Testing *****//
+
+func TestClientCredentials_GetDownloadURL(t *testing.T) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/files/download?file=%2Fworld.zip"
+		if expectURL != url {
+			t.Errorf("Request url does not match expected: %s", url)
+		}
+
+		return []byte(`{"object":"signed_url","attributes":{"url":"https://node.example.com:8080/download/file?token=abc"}}`), nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	got, err := c.GetDownloadURL("1a7ce997", "/world.zip")
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	if got != "https://node.example.com:8080/download/file?token=abc" {
+		t.Errorf("Unexpected response: %s", got)
+	}
+}
+
+func TestClientCredentials_UploadFile(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("token") != "abc" || r.URL.Query().Get("directory") != "/plugins" {
+			t.Errorf("Unexpected upload url: %s", r.URL)
+		}
+
+		f, header, err := r.FormFile("files")
+		if err != nil {
+			t.Errorf("Error: %s", err.Error())
+			return
+		}
+
+		content, _ := ioutil.ReadAll(f)
+		if header.Filename != "plugin.jar" || string(content) != "plugin contents" {
+			t.Errorf("Unexpected upload: %s %s", header.Filename, content)
+		}
+	}))
+	defer srv.Close()
+
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		expectURL := "https://example.com/api/client/servers/1a7ce997/files/upload"
+		if expectURL != url {
+			t.Errorf("Request url does not match expected: %s", url)
+		}
+
+		return []byte(`{"object":"signed_url","attributes":{"url":"` + srv.URL + `/upload/file?token=abc"}}`), nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	var last, total int64
+	err := c.UploadFile("1a7ce997", "/plugins", "plugin.jar", strings.NewReader("plugin contents"),
+		func(transferred, size int64) {
+			last, total = transferred, size
+		})
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	if last != 15 || total != 15 {
+		t.Errorf("Unexpected progress: %d/%d", last, total)
+	}
+}
+
+func TestClientCredentials_DownloadFile(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("token") != "abc" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		_, _ = w.Write([]byte("world contents"))
+	}))
+	defer srv.Close()
+
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		return []byte(`{"object":"signed_url","attributes":{"url":"` + srv.URL + `/download/file?token=abc"}}`), nil
+	}
+
+	c := NewClient("https://example.com", "")
+
+	var buf bytes.Buffer
+	var last int64
+	n, err := c.DownloadFile("1a7ce997", "/world.zip", &buf, func(transferred, total int64) {
+		last = transferred
+	})
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	if n != 14 || last != 14 || buf.String() != "world contents" {
+		t.Errorf("Unexpected download: %d bytes, %s", n, buf.String())
+	}
+
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		return []byte(`{"object":"signed_url","attributes":{"url":"` + srv.URL + `/download/file?token=expired"}}`), nil
+	}
+
+	_, err = c.DownloadFile("1a7ce997", "/world.zip", &buf, nil)
+	if !IsForbidden(err) {
+		t.Errorf("Expected a forbidden error, got: %v", err)
+	}
+}
+
+func TestClientCredentials_DownloadFile_Timeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A slow transfer, taking longer than the timeout of the credentials
+		for _, chunk := range []string{"world ", "contents"} {
+			_, _ = w.Write([]byte(chunk))
+			w.(http.Flusher).Flush()
+			time.Sleep(60 * time.Millisecond)
+		}
+	}))
+	defer srv.Close()
+
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
+		return []byte(`{"object":"signed_url","attributes":{"url":"` + srv.URL + `/download/file"}}`), nil
+	}
+
+	c := NewClient("https://example.com", "", WithTimeout(50*time.Millisecond))
+
+	var buf bytes.Buffer
+	_, err := c.DownloadFile("1a7ce997", "/world.zip", &buf, nil)
+	if err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+
+	if buf.String() != "world contents" {
+		t.Errorf("Unexpected download: %s", buf.String())
+	}
+
+	if (*Credentials)(c).client().Timeout != 50*time.Millisecond {
+		t.Error("The timeout of the credentials was modified")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = c.DownloadFileContext(ctx, "1a7ce997", "/world.zip", &buf, nil)
+	if err == nil {
+		t.Error("Expected the context to end the download")
+	}
+}
@@ -1,6 +1,7 @@
 package fossil
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -24,82 +25,66 @@ type User struct {
 	UpdatedAt               time.Time `json:"updated_at"`
 }
 
-// jsonUserPage contains a page of Users and the pagination data.
-// It's used as the target struct in the marshalling/unmarshalling of API requests or responses.
-type jsonUserPage struct {
-	Data []struct {
-		User *User `json:"attributes"`
-	} `json:"data"`
-	Meta Meta `json:"meta"`
-}
-
 //***** Pagination *****//
 
-// getAll fetches all the existing pages for a user list. The original page is kept as index 0
-func (up *jsonUserPage) getAll(token string) (pages []*jsonUserPage, err error) {
-	pages = append(pages, up)
-	for pages[len(pages)-1].Meta.Pagination.Links.Next != "" {
-		url := up.Meta.Pagination.Links.Next
-		bytes, err := queryURL(url, token, "GET", nil)
-		if err != nil {
-			return nil, err
-		}
-
-		var page jsonUserPage
-		err = json.Unmarshal(bytes, &page)
-		if err != nil {
-			return nil, err
-		}
+// UserIterator lazily walks a list of users, fetching the pages as needed
+type UserIterator struct {
+	pager
+	user *User
+}
 
-		pages = append(pages, &page)
+// Next advances to the next user, returning false when there are no more or an error occurred
+func (it *UserIterator) Next() bool {
+	var v User
+	if !it.next(&v) {
+		return false
 	}
 
-	return pages, nil
+	it.user = &v
+	return true
 }
 
-//***** Converters *****//
-
-// asUserSlice parses a jsonUserPage into a []*User
-func (up *jsonUserPage) asUserSlice() (users []*User) {
-	for _, u := range up.Data {
-		users = append(users, u.User)
-	}
-
-	return users
+// Value returns the current user
+func (it *UserIterator) Value() *User {
+	return it.user
 }
 
 //***** Requests *****//
 
-// GetUsers fetches all the registered users from the API
-func (c *ApplicationCredentials) GetUsers() (users []*User, err error) {
-	bytes, err := c.query("users", "GET", nil)
-	if err != nil {
-		return
-	}
+// GetUsers fetches all the registered users from the API. The results can be optionally filtered and sorted
+// with ListOptions.
+func (c *ApplicationCredentials) GetUsers(opts ...*ListOptions) ([]*User, error) {
+	return c.GetUsersContext(context.Background(), opts...)
+}
 
-	// Get the initial page
-	var page jsonUserPage
-	err = json.Unmarshal(bytes, &page)
-	if err != nil {
-		return
+// GetUsersContext is GetUsers with a context
+func (c *ApplicationCredentials) GetUsersContext(ctx context.Context, opts ...*ListOptions) (users []*User, err error) {
+	it := c.UsersIterContext(ctx, listOptions(opts))
+	for it.Next() {
+		users = append(users, it.Value())
 	}
 
-	// Search for the remaining pages if present
-	pages, err := page.getAll(c.Token)
-	if err != nil {
-		return
-	}
+	return users, it.Err()
+}
 
-	for _, page := range pages {
-		users = append(users, page.asUserSlice()...)
-	}
+// UsersIter returns an iterator over all the registered users. The pages are fetched as they are needed.
+func (c *ApplicationCredentials) UsersIter(opts *ListOptions) *UserIterator {
+	return c.UsersIterContext(context.Background(), opts)
+}
 
-	return
+// UsersIterContext is UsersIter with a context
+func (c *ApplicationCredentials) UsersIterContext(ctx context.Context, opts *ListOptions) *UserIterator {
+	return &UserIterator{pager: newPager(ctx, (*Credentials)(c), c.endpointURL("users"), opts)}
 }
 
 // GetUser fetches, if present, the user with the matching Internal ID
-func (c *ApplicationCredentials) GetUser(id int) (user *User, err error) {
-	bytes, err := c.query(fmt.Sprintf("users/%d", id), "GET", nil)
+func (c *ApplicationCredentials) GetUser(id int) (*User, error) {
+	return c.GetUserContext(context.Background(), id)
+}
+
+// GetUserContext is GetUser with a context
+func (c *ApplicationCredentials) GetUserContext(ctx context.Context, id int) (user *User, err error) {
+	bytes, err := c.query(ctx, fmt.Sprintf("users/%d", id), "GET", nil)
 	if err != nil {
 		return
 	}
@@ -117,8 +102,13 @@ func (c *ApplicationCredentials) GetUser(id int) (user *User, err error) {
 }
 
 // GetUserExternal fetches, if present, the user with the matching External ID
-func (c *ApplicationCredentials) GetUserExternal(eid string) (user *User, err error) {
-	bytes, err := c.query(fmt.Sprintf("users/external/%s", eid), "GET", nil)
+func (c *ApplicationCredentials) GetUserExternal(eid string) (*User, error) {
+	return c.GetUserExternalContext(context.Background(), eid)
+}
+
+// GetUserExternalContext is GetUserExternal with a context
+func (c *ApplicationCredentials) GetUserExternalContext(ctx context.Context, eid string) (user *User, err error) {
+	bytes, err := c.query(ctx, fmt.Sprintf("users/external/%s", eid), "GET", nil)
 	if err != nil {
 		return
 	}
@@ -136,7 +126,25 @@ func (c *ApplicationCredentials) GetUserExternal(eid string) (user *User, err er
 }
 
 // CreateUser makes a new account with the provided data. The password argument can be optionally set.
-func (c *ApplicationCredentials) CreateUser(u *User, password ...string) (err error) {
+func (c *ApplicationCredentials) CreateUser(u *User, password ...string) error {
+	return c.CreateUserContext(context.Background(), u, password...)
+}
+
+// CreateUserContext is CreateUser with a context
+func (c *ApplicationCredentials) CreateUserContext(ctx context.Context, u *User, password ...string) (err error) {
+	_, err = c.CreateUserReturningContext(ctx, u, password...)
+	return
+}
+
+// CreateUserReturning is CreateUser, but returns the user as created by the panel, with its ID, UUID and dates.
+// The passed user is left untouched.
+func (c *ApplicationCredentials) CreateUserReturning(u *User, password ...string) (*User, error) {
+	return c.CreateUserReturningContext(context.Background(), u, password...)
+}
+
+// CreateUserReturningContext is CreateUserReturning with a context
+func (c *ApplicationCredentials) CreateUserReturningContext(ctx context.Context, u *User,
+	password ...string) (created *User, err error) {
 	type wrapper struct {
 		ExternalID string `json:"external_id,omitempty"`
 		Username   string `json:"username"`
@@ -164,19 +172,37 @@ func (c *ApplicationCredentials) CreateUser(u *User, password ...string) (err er
 		Language:   u.Language,
 	}
 
-	bytes, err := json.Marshal(usrStruct)
+	rq, err := json.Marshal(usrStruct)
 	if err != nil {
-		return err
+		return
 	}
 
-	_, err = c.query("users", "POST", bytes)
-	return
+	bytes, err := c.query(ctx, "users", "POST", rq)
+	if err != nil {
+		return
+	}
+
+	var fWrapper struct {
+		User *User `json:"attributes"`
+	}
+
+	err = json.Unmarshal(bytes, &fWrapper)
+	if err != nil {
+		return
+	}
+
+	return fWrapper.User, nil
 }
 
 // UpdateUser modifies the user as per the passed object. Be aware that not all parameters can be
 // modified. Modifiable parameters include: External ID, Username, First name, Last name, Password, Root admin
 // and Language. The password parameter can be optionally set.
-func (c *ApplicationCredentials) UpdateUser(u *User, password ...string) (err error) {
+func (c *ApplicationCredentials) UpdateUser(u *User, password ...string) error {
+	return c.UpdateUserContext(context.Background(), u, password...)
+}
+
+// UpdateUserContext is UpdateUser with a context
+func (c *ApplicationCredentials) UpdateUserContext(ctx context.Context, u *User, password ...string) (err error) {
 	type wrapper struct {
 		ExternalID string `json:"external_id,omitempty"`
 		Username   string `json:"username"`
@@ -209,12 +235,17 @@ func (c *ApplicationCredentials) UpdateUser(u *User, password ...string) (err er
 		return err
 	}
 
-	_, err = c.query(fmt.Sprintf("users/%d", u.ID), "PATCH", bytes)
+	_, err = c.query(ctx, fmt.Sprintf("users/%d", u.ID), "PATCH", bytes)
 	return
 }
 
 // DeleteUser marks a user for deletion.
-func (c *ApplicationCredentials) DeleteUser(id int) (err error) {
-	_, err = c.query(fmt.Sprintf("users/%d", id), "DELETE", nil)
+func (c *ApplicationCredentials) DeleteUser(id int) error {
+	return c.DeleteUserContext(context.Background(), id)
+}
+
+// DeleteUserContext is DeleteUser with a context
+func (c *ApplicationCredentials) DeleteUserContext(ctx context.Context, id int) (err error) {
+	_, err = c.query(ctx, fmt.Sprintf("users/%d", id), "DELETE", nil)
 	return
 }
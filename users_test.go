@@ -1,13 +1,14 @@
 package fossil
 
 import (
+	"context"
 	"github.com/google/go-cmp/cmp"
 	"testing"
 	"time"
 )
 
 func TestApplicationCredentials_GetUsers(t *testing.T) {
-	query = func(url, token, method string, data []byte) ([]byte, error) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
 		expectURL := "https://example.com/api/application/users"
 		if expectURL != url {
 			t.Errorf("Request url does not match expected: %s", url)
@@ -187,7 +188,7 @@ func TestApplicationCredentials_GetUsers(t *testing.T) {
 }
 
 func TestApplicationCredentials_GetUser(t *testing.T) {
-	query = func(url, token, method string, data []byte) ([]byte, error) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
 		expectURL := "https://example.com/api/application/users/1"
 		if expectURL != url {
 			t.Errorf("Request url does not match expected: %s", url)
@@ -245,7 +246,7 @@ func TestApplicationCredentials_GetUser(t *testing.T) {
 }
 
 func TestApplicationCredentials_GetUserExternal(t *testing.T) {
-	query = func(url, token, method string, data []byte) ([]byte, error) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
 		expectURL := "https://example.com/api/application/users/external/1"
 		if expectURL != url {
 			t.Errorf("Request url does not match expected: %s", url)
@@ -303,7 +304,7 @@ func TestApplicationCredentials_GetUserExternal(t *testing.T) {
 }
 
 func TestApplicationCredentials_CreateUser(t *testing.T) {
-	query = func(url, token, method string, data []byte) ([]byte, error) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
 		expectURL := "https://example.com/api/application/users"
 		if expectURL != url {
 			t.Errorf("Request url does not match expected: %s", url)
@@ -316,7 +317,25 @@ func TestApplicationCredentials_CreateUser(t *testing.T) {
 			t.Errorf("Request data does not match expected: %s", string(data))
 		}
 
-		return nil, nil
+		res := `{
+		  "object": "user",
+		  "attributes": {
+			"id": 4,
+			"external_id": "example_ext_id",
+			"uuid": "f253663c-5a45-43a8-b280-3ea3c752b931",
+			"username": "example",
+			"email": "example@example.com",
+			"first_name": "John",
+			"last_name": "Doe",
+			"language": "en",
+			"root_admin": false,
+			"2fa": false,
+			"created_at": "2018-03-18T15:15:17+00:00",
+			"updated_at": "2018-10-16T21:51:21+00:00"
+		  }
+		}`
+
+		return []byte(res), nil
 	}
 
 	a := NewApplication("https://example.com", "")
@@ -331,14 +350,27 @@ func TestApplicationCredentials_CreateUser(t *testing.T) {
 		RootAdmin:  false,
 	}
 
-	err := a.CreateUser(user, "cat")
+	got, err := a.CreateUserReturning(user, "cat")
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	if got.ID != 4 || got.UUID != "f253663c-5a45-43a8-b280-3ea3c752b931" {
+		t.Errorf("Unexpected response: %+v", got)
+	}
+
+	if user.ID != 0 || user.UUID != "" {
+		t.Errorf("The passed user was modified: %+v", user)
+	}
+
+	err = a.CreateUser(user, "cat")
 	if err != nil {
 		t.Errorf("Error: %s", err.Error())
 	}
 }
 
 func TestApplicationCredentials_UpdateUser(t *testing.T) {
-	query = func(url, token, method string, data []byte) ([]byte, error) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
 		expectURL := "https://example.com/api/application/users/1"
 		if expectURL != url {
 			t.Errorf("Request url does not match expected: %s", url)
@@ -373,7 +405,7 @@ func TestApplicationCredentials_UpdateUser(t *testing.T) {
 }
 
 func TestApplicationCredentials_DeleteUser(t *testing.T) {
-	query = func(url, token, method string, data []byte) ([]byte, error) {
+	query = func(ctx context.Context, c *Credentials, url, method string, data []byte) ([]byte, error) {
 		expectURL := "https://example.com/api/application/users/1"
 		if expectURL != url {
 			t.Errorf("Request url does not match expected: %s", url)